@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/arsham/gitrelease/commit"
+	"github.com/spf13/viper"
+)
+
+// announce comments on the issues and pull requests referenced in the notes
+// that they are released, if it is enabled. The failures are only reported to
+// w, as the release is already published.
+func announce(ctx context.Context, w io.Writer, g *commit.Git, token, user, repo, tag, url, desc string) error {
+	if !viper.GetBool("comments.enabled") {
+		return nil
+	}
+	numbers := commit.IssueRefs(desc)
+	if len(numbers) == 0 {
+		return nil
+	}
+	a := announcer(g, token, user, repo)
+	errs := a.Announce(ctx, tag, url, numbers)
+	if len(errs) == 0 {
+		return nil
+	}
+	if _, err := fmt.Fprintf(w, "warning: commenting on %d of %d issues failed:\n", len(errs), len(numbers)); err != nil {
+		return err
+	}
+	for _, err := range errs {
+		if _, err := fmt.Fprintln(w, err); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// announcer returns the Announcer in the configuration file.
+func announcer(g *commit.Git, token, user, repo string) commit.Announcer {
+	retries := 3
+	if viper.IsSet("comments.retries") {
+		retries = viper.GetInt("comments.retries")
+	}
+	return commit.Announcer{
+		Git:         g,
+		Token:       token,
+		User:        user,
+		Repo:        repo,
+		Message:     viper.GetString("comments.message"),
+		Label:       viper.GetString("comments.label"),
+		Concurrency: viper.GetInt("comments.concurrency"),
+		Retries:     retries,
+	}
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"io"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/arsham/gitrelease/commit"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+var (
+	backfillDryRun bool
+	backfillDelay  time.Duration
+
+	backfillCmd = &cobra.Command{
+		Use:   "backfill",
+		Short: "Create missing releases for all tags",
+		Long: `Walks every tag in version order, generates the release notes between each tag
+and its predecessor, and creates the releases that don't exist yet. Existing
+releases are skipped, therefore running the command again after an interruption
+resumes from where it was stopped.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, cancel := signal.NotifyContext(cmd.Context(), syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
+			defer cancel()
+			token, err := githubToken(ctx, backfillDryRun)
+			if err != nil {
+				return err
+			}
+			g, err := newGit()
+			if err != nil {
+				return err
+			}
+			return backfill(ctx, cmd.OutOrStdout(), g, token)
+		},
+	}
+)
+
+func init() {
+	backfillCmd.Flags().BoolVar(&backfillDryRun, "dry-run", false, "only print a summary of the releases that would be created")
+	backfillCmd.Flags().DurationVar(&backfillDelay, "delay", time.Second, "wait between creating releases to avoid secondary rate limits")
+	rootCmd.AddCommand(backfillCmd)
+}
+
+func backfill(ctx context.Context, w io.Writer, g *commit.Git, token string) error {
+	user, repo, err := g.RepoInfo(ctx)
+	if err != nil {
+		return errors.Wrap(err, "can't get repo name")
+	}
+	opts, err := notesOptions()
+	if err != nil {
+		return err
+	}
+	b := commit.Backfiller{
+		Git:     g,
+		Token:   token,
+		User:    user,
+		Repo:    repo,
+		Options: opts,
+		DryRun:  backfillDryRun,
+		Delay:   backfillDelay,
+		Out:     w,
+	}
+	return b.Backfill(ctx)
+}
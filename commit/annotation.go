@@ -0,0 +1,54 @@
+package commit
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Annotation is the message of an annotated tag and who created it.
+type Annotation struct {
+	// Tagger is in the "Name <email>" form.
+	Tagger  string
+	Date    time.Time
+	Message string
+}
+
+var signatureRe = regexp.MustCompile(`(?s)-----BEGIN (?:PGP|SSH) SIGNATURE-----.*?-----END (?:PGP|SSH) SIGNATURE-----\n?`)
+
+// StripSignature removes the PGP and SSH signature blocks from the message.
+func StripSignature(msg string) string {
+	return strings.TrimSpace(signatureRe.ReplaceAllString(msg, ""))
+}
+
+// TagAnnotation returns the annotation of the tag, without its signature. It
+// returns nil if the tag is not annotated.
+func (g Git) TagAnnotation(ctx context.Context, tag string) (*Annotation, error) {
+	a, err := g.repo().TagAnnotation(ctx, tag)
+	if err != nil || a == nil {
+		return nil, err
+	}
+	a.Message = StripSignature(a.Message)
+	return a, nil
+}
+
+// Introduction returns the annotation as the introduction of the release
+// notes. If withTagger is true, the tagger's name and the date are added after
+// the message.
+func (a Annotation) Introduction(withTagger bool) string {
+	intro := strings.TrimSpace(a.Message)
+	if !withTagger || a.Tagger == "" {
+		return intro
+	}
+	name := a.Tagger
+	if i := strings.Index(name, " <"); i > 0 {
+		name = name[:i]
+	}
+	line := fmt.Sprintf("_Tagged by %s on %s._", name, a.Date.UTC().Format("2006-01-02"))
+	if intro == "" {
+		return line
+	}
+	return intro + "\n\n" + line
+}
@@ -0,0 +1,43 @@
+package commit_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/arsham/gitrelease/commit"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStripSignature(t *testing.T) {
+	t.Parallel()
+	tcs := map[string]struct {
+		msg  string
+		want string
+	}{
+		"none":   {msg: "A summary.\n", want: "A summary."},
+		"pgp":    {msg: "A summary.\n-----BEGIN PGP SIGNATURE-----\n\niQEz\n=ab\n-----END PGP SIGNATURE-----\n", want: "A summary."},
+		"ssh":    {msg: "A summary.\n\n-----BEGIN SSH SIGNATURE-----\nU1NI\n-----END SSH SIGNATURE-----", want: "A summary."},
+		"middle": {msg: "A\n-----BEGIN PGP SIGNATURE-----\nx\n-----END PGP SIGNATURE-----\nB", want: "A\nB"},
+	}
+	for name, tc := range tcs {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tc.want, commit.StripSignature(tc.msg))
+		})
+	}
+}
+
+func TestAnnotationIntroduction(t *testing.T) {
+	t.Parallel()
+	a := commit.Annotation{
+		Tagger:  "Arsham Shirvani <arsham@github.com>",
+		Date:    time.Date(2022, 5, 14, 23, 0, 0, 0, time.UTC),
+		Message: "A summary.\n",
+	}
+	assert.Equal(t, "A summary.", a.Introduction(false))
+	assert.Equal(t, "A summary.\n\n_Tagged by Arsham Shirvani on 2022-05-14._", a.Introduction(true))
+
+	a.Message = ""
+	assert.Equal(t, "_Tagged by Arsham Shirvani on 2022-05-14._", a.Introduction(true))
+}
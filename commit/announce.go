@@ -0,0 +1,180 @@
+package commit
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// refNumRe matches the references to the issues and pull requests of the same
+// repository, e.g. "#12" but not "user/repo#12" or "a#12".
+var refNumRe = regexp.MustCompile(`(?:^|[^[:alnum:]_/#])#(\d+)\b`)
+
+// IssueRefs returns the unique numbers of the issues and pull requests
+// referenced in the text, in ascending order.
+func IssueRefs(text string) []int {
+	seen := make(map[int]struct{})
+	var ret []int
+	for _, m := range refNumRe.FindAllStringSubmatch(text, -1) {
+		n, err := strconv.Atoi(m[1])
+		if err != nil || n == 0 {
+			continue
+		}
+		if _, ok := seen[n]; ok {
+			continue
+		}
+		seen[n] = struct{}{}
+		ret = append(ret, n)
+	}
+	sort.Ints(ret)
+	return ret
+}
+
+// Announcer comments on the issues and pull requests that are released.
+type Announcer struct {
+	Git   *Git
+	Token string
+	User  string
+	Repo  string
+	// Message is the comment. The {tag} and {url} placeholders are replaced
+	// with the tag and the address of the release. Defaults to "Released in
+	// {tag}."
+	Message string
+	// Label is added to the issues if not empty.
+	Label string
+	// Concurrency is the number of issues handled at the same time. Defaults
+	// to 4.
+	Concurrency int
+	// Retries is the number of times a request is retried after it is rate
+	// limited.
+	Retries int
+}
+
+// announceMarker is hidden in the comments for finding the issues that are
+// already commented on for the tag.
+func announceMarker(tag string) string {
+	return fmt.Sprintf("<!-- gitrelease:%s -->", tag)
+}
+
+// Announce comments on the issues with the numbers that the tag is released,
+// unless they already have the comment. It returns the errors of the issues it
+// couldn't comment on.
+func (a Announcer) Announce(ctx context.Context, tag, url string, numbers []int) []error {
+	concurrency := a.Concurrency
+	if concurrency <= 0 {
+		concurrency = 4
+	}
+	var (
+		mu   sync.Mutex
+		errs []error
+		wg   sync.WaitGroup
+		sem  = make(chan struct{}, concurrency)
+	)
+	for _, n := range numbers {
+		n := n
+		select {
+		case <-ctx.Done():
+			mu.Lock()
+			errs = append(errs, errors.Wrapf(ctx.Err(), "#%d", n))
+			mu.Unlock()
+			continue
+		case sem <- struct{}{}:
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			if err := a.announce(ctx, tag, url, n); err != nil {
+				mu.Lock()
+				errs = append(errs, errors.Wrapf(err, "#%d", n))
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	sort.SliceStable(errs, func(i, j int) bool {
+		return errs[i].Error() < errs[j].Error()
+	})
+	return errs
+}
+
+func (a Announcer) announce(ctx context.Context, tag, url string, number int) error {
+	path := fmt.Sprintf("/repos/%s/%s/issues/%d", a.User, a.Repo, number)
+	marker := announceMarker(tag)
+
+	var comments []struct {
+		Body string `json:"body"`
+	}
+	err := a.retry(ctx, func() error {
+		comments = comments[:0]
+		return a.Git.client(a.Token, a.Repo).Get(path+"/comments", &comments)
+	})
+	if err != nil {
+		return errors.Wrap(err, "listing comments")
+	}
+	for _, c := range comments {
+		if strings.Contains(c.Body, marker) {
+			return nil
+		}
+	}
+
+	calls := a.Calls(tag, url, number)
+	err = a.retry(ctx, func() error {
+		return a.Git.send(ctx, a.Token, a.Repo, calls[0])
+	})
+	if err != nil {
+		return errors.Wrap(err, "commenting")
+	}
+	if len(calls) == 1 {
+		return nil
+	}
+	err = a.retry(ctx, func() error {
+		return a.Git.send(ctx, a.Token, a.Repo, calls[1])
+	})
+	return errors.Wrap(err, "adding label")
+}
+
+// Calls returns the API calls Announce makes for commenting on the issue with
+// the number, and for adding the Label to it. They are not made if the issue
+// already has the comment.
+func (a Announcer) Calls(tag, url string, number int) []APICall {
+	path := fmt.Sprintf("/repos/%s/%s/issues/%d", a.User, a.Repo, number)
+	msg := a.Message
+	if msg == "" {
+		msg = "Released in {tag}."
+	}
+	msg = strings.NewReplacer("{tag}", tag, "{url}", url).Replace(msg)
+	calls := []APICall{
+		newCall(http.MethodPost, path+"/comments", map[string]string{"body": msg + "\n\n" + announceMarker(tag)}),
+	}
+	if a.Label != "" {
+		calls = append(calls, newCall(http.MethodPost, path+"/labels", map[string][]string{"labels": {a.Label}}))
+	}
+	return calls
+}
+
+// retry calls fn until it is not rate limited, or it runs out of retries.
+func (a Announcer) retry(ctx context.Context, fn func() error) error {
+	for attempt := 0; ; attempt++ {
+		err := fn()
+		var ae *APIError
+		if !errors.As(err, &ae) || !ae.RateLimited || attempt >= a.Retries {
+			return err
+		}
+		timer := time.NewTimer(ae.RetryAfter)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
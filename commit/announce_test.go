@@ -0,0 +1,192 @@
+package commit_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/arsham/gitrelease/commit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIssueRefs(t *testing.T) {
+	t.Parallel()
+	tcs := map[string]struct {
+		text string
+		want []int
+	}{
+		"none":        {text: "### Fix\n\n- Something", want: nil},
+		"one":         {text: "- Fix x (#12)", want: []int{12}},
+		"sorted":      {text: "- Fix x (#12)\n- Fix y (Closes #3, #12)", want: []int{3, 12}},
+		"start":       {text: "#7 is fixed", want: []int{7}},
+		"other repo":  {text: "- Fix x (user/repo#12)", want: nil},
+		"not a ref":   {text: "- Fix a#12 and ##3 and #0", want: nil},
+		"issue links": {text: "- Fix [PLAT-1](https://jira/browse/PLAT-1#1)", want: nil},
+	}
+	for name, tc := range tcs {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tc.want, commit.IssueRefs(tc.text))
+		})
+	}
+}
+
+func TestAnnouncer(t *testing.T) {
+	t.Parallel()
+	t.Run("Announce", testAnnouncerAnnounce)
+	t.Run("RateLimit", testAnnouncerRateLimit)
+	t.Run("Errors", testAnnouncerErrors)
+	t.Run("Calls", testAnnouncerCalls)
+}
+
+type issueServer struct {
+	mu       sync.Mutex
+	comments map[string][]string
+	labels   map[string][]string
+	limited  int
+}
+
+func (s *issueServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.limited > 0 {
+		s.limited--
+		w.Header().Set("Retry-After", "0")
+		w.WriteHeader(http.StatusForbidden)
+		fmt.Fprint(w, `{"message": "You have exceeded a secondary rate limit"}`)
+		return
+	}
+
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/repos/arsham/gitrelease/issues/"), "/")
+	if len(parts) != 2 || parts[0] == "404" {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"message": "Not Found"}`)
+		return
+	}
+	issue := parts[0]
+	switch {
+	case parts[1] == "comments" && r.Method == http.MethodGet:
+		comments := make([]map[string]string, 0, len(s.comments[issue]))
+		for _, c := range s.comments[issue] {
+			comments = append(comments, map[string]string{"body": c})
+		}
+		json.NewEncoder(w).Encode(comments) // nolint:errcheck // test server.
+	case parts[1] == "comments" && r.Method == http.MethodPost:
+		var v map[string]string
+		if err := json.NewDecoder(r.Body).Decode(&v); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		s.comments[issue] = append(s.comments[issue], v["body"])
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, `{}`)
+	case parts[1] == "labels" && r.Method == http.MethodPost:
+		var v map[string][]string
+		if err := json.NewDecoder(r.Body).Decode(&v); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		s.labels[issue] = append(s.labels[issue], v["labels"]...)
+		fmt.Fprint(w, `[]`)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func newIssueServer(t *testing.T) (*issueServer, *commit.Git) {
+	t.Helper()
+	s := &issueServer{
+		comments: map[string][]string{},
+		labels:   map[string][]string{},
+	}
+	srv := httptest.NewServer(s)
+	t.Cleanup(srv.Close)
+	return s, &commit.Git{BaseURL: srv.URL}
+}
+
+func testAnnouncerAnnounce(t *testing.T) {
+	t.Parallel()
+	s, g := newIssueServer(t)
+	s.comments["2"] = []string{"Released in v0.1.0.\n\n<!-- gitrelease:v0.1.0 -->"}
+	a := commit.Announcer{
+		Git:         g,
+		User:        "arsham",
+		Repo:        "gitrelease",
+		Message:     "Released in [{tag}]({url}).",
+		Label:       "released",
+		Concurrency: 2,
+	}
+
+	errs := a.Announce(context.Background(), "v0.2.0", "https://example.com", []int{1, 2, 3})
+	require.Empty(t, errs)
+	for _, issue := range []string{"1", "2", "3"} {
+		comments := s.comments[issue]
+		require.NotEmpty(t, comments, issue)
+		assert.Equal(t, "Released in [v0.2.0](https://example.com).\n\n<!-- gitrelease:v0.2.0 -->", comments[len(comments)-1])
+		assert.Equal(t, []string{"released"}, s.labels[issue])
+	}
+
+	// Running again should not add more comments.
+	errs = a.Announce(context.Background(), "v0.2.0", "https://example.com", []int{1, 2, 3})
+	require.Empty(t, errs)
+	assert.Len(t, s.comments["1"], 1)
+	assert.Len(t, s.comments["2"], 2)
+	assert.Len(t, s.labels["1"], 1)
+}
+
+func testAnnouncerRateLimit(t *testing.T) {
+	t.Parallel()
+	s, g := newIssueServer(t)
+	s.limited = 2
+	a := commit.Announcer{Git: g, User: "arsham", Repo: "gitrelease", Retries: 2}
+
+	errs := a.Announce(context.Background(), "v0.2.0", "", []int{1})
+	require.Empty(t, errs)
+	assert.Equal(t, []string{"Released in v0.2.0.\n\n<!-- gitrelease:v0.2.0 -->"}, s.comments["1"])
+	assert.Empty(t, s.labels)
+
+	s.limited = 1
+	a.Retries = 0
+	errs = a.Announce(context.Background(), "v0.3.0", "", []int{1})
+	require.Len(t, errs, 1)
+	assert.Contains(t, errs[0].Error(), "403")
+}
+
+func testAnnouncerErrors(t *testing.T) {
+	t.Parallel()
+	s, g := newIssueServer(t)
+	a := commit.Announcer{Git: g, User: "arsham", Repo: "gitrelease"}
+
+	errs := a.Announce(context.Background(), "v0.2.0", "", []int{1, 404})
+	require.Len(t, errs, 1)
+	assert.Contains(t, errs[0].Error(), "#404")
+	assert.Len(t, s.comments["1"], 1)
+}
+
+func testAnnouncerCalls(t *testing.T) {
+	t.Parallel()
+	a := commit.Announcer{User: "arsham", Repo: "gitrelease", Message: "Shipped in {tag}."}
+	var got []string
+	for _, call := range a.Calls("v0.1.0", "https://example.com", 12) {
+		got = append(got, call.String())
+	}
+	want := []string{
+		`POST /repos/arsham/gitrelease/issues/12/comments {"body":"Shipped in v0.1.0.\n\n\u003c!-- gitrelease:v0.1.0 --\u003e"}`,
+	}
+	assert.Equal(t, want, got)
+
+	a.Label = "released"
+	got = got[:0]
+	for _, call := range a.Calls("v0.1.0", "https://example.com", 12) {
+		got = append(got, call.String())
+	}
+	want = append(want, `POST /repos/arsham/gitrelease/issues/12/labels {"labels":["released"]}`)
+	assert.Equal(t, want, got)
+}
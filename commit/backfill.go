@@ -0,0 +1,137 @@
+package commit
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Backfiller creates the missing releases of all tags of the repository.
+type Backfiller struct {
+	Git   *Git
+	Token string
+	User  string
+	Repo  string
+	// Options are used for rendering the notes.
+	Options []Option
+	// DryRun only reports the releases that would be created.
+	DryRun bool
+	// Delay is the wait between creating the releases, which avoids the
+	// secondary rate limits.
+	Delay time.Duration
+	// Out receives a line for each release, and a summary at the end. Nothing
+	// is reported if nil.
+	Out io.Writer
+}
+
+// Backfill walks the tags in version order, and creates the releases that
+// don't exist yet with the notes between each tag and the one before it. The
+// existing releases are skipped, therefore running it again after an
+// interruption resumes from where it was stopped.
+func (b Backfiller) Backfill(ctx context.Context) error {
+	out := b.Out
+	if out == nil {
+		out = io.Discard
+	}
+	tags, err := b.Git.Tags(ctx)
+	if err != nil {
+		return errors.Wrap(err, "listing tags")
+	}
+
+	releases, err := b.Git.Releases(ctx, b.Token, b.User, b.Repo)
+	if err != nil {
+		return err
+	}
+	existing := make(map[string]struct{}, len(releases))
+	for _, r := range releases {
+		existing[r.TagName] = struct{}{}
+	}
+
+	var created, skipped int
+	for i, tag := range tags {
+		if _, ok := existing[tag]; ok {
+			skipped++
+			continue
+		}
+		var prev string
+		if i > 0 {
+			prev = tags[i-1]
+		}
+
+		commits, err := b.Git.Commits(ctx, prev, tag)
+		if err != nil {
+			return errors.Wrapf(err, "getting commits of %s", tag)
+		}
+		desc := ParseGroups(commits, b.Options...)
+
+		if b.DryRun {
+			fmt.Fprintf(out, "would create %s (%d commits since %q)\n", tag, len(commits), prev)
+			created++
+			continue
+		}
+
+		if created > 0 {
+			if err := sleep(ctx, b.Delay); err != nil {
+				return err
+			}
+		}
+		if err := b.waitRateLimit(ctx, out); err != nil {
+			return err
+		}
+		err = b.Git.Release(ctx, b.Token, b.User, b.Repo, tag, desc)
+		if errors.Is(err, ErrReleaseExists) {
+			// It was created after the releases were listed.
+			skipped++
+			continue
+		}
+		if err != nil {
+			return errors.Wrapf(err, "creating release for %s (%d created so far, run again to resume)", tag, created)
+		}
+		fmt.Fprintf(out, "created %s\n", tag)
+		created++
+	}
+
+	verb := "created"
+	if b.DryRun {
+		verb = "would create"
+	}
+	fmt.Fprintf(out, "%s %d releases, skipped %d existing out of %d tags\n", verb, created, skipped, len(tags))
+	return nil
+}
+
+// waitRateLimit blocks until the token can make at least one more request.
+func (b Backfiller) waitRateLimit(ctx context.Context, out io.Writer) error {
+	limit, err := b.Git.RateLimit(ctx, b.Token)
+	if err != nil {
+		return err
+	}
+	if limit.Remaining > 0 {
+		return nil
+	}
+	wait := time.Until(limit.Reset)
+	if wait < 0 {
+		// The clocks are skewed, and the limit has already been reset.
+		wait = 0
+	}
+	fmt.Fprintf(out, "rate limit exhausted, waiting %s until it resets\n", wait.Round(time.Second))
+	return sleep(ctx, wait)
+}
+
+// sleep waits for the duration d, or returns early if the context is
+// cancelled.
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,117 @@
+package commit_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/arsham/gitrelease/commit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBackfillerBackfill(t *testing.T) {
+	t.Parallel()
+	dir := createGitRepo(t)
+	for i, tag := range []string{"v0.0.1", "v0.0.2", "v0.0.3", "v0.0.4"} {
+		createFile(t, dir, "file", tag)
+		commitChanges(t, dir, fmt.Sprintf("feat: change %d", i))
+		createGitTag(t, dir, tag)
+	}
+
+	tcs := map[string]struct {
+		dryRun    bool
+		remaining int
+		want      []string
+		wantPosts []string
+	}{
+		"create": {
+			remaining: 10,
+			want: []string{
+				"created v0.0.2",
+				"created v0.0.4",
+				"created 2 releases, skipped 2 existing out of 4 tags",
+			},
+			wantPosts: []string{"v0.0.2", "v0.0.3", "v0.0.4"},
+		},
+		"rate limited": {
+			remaining: 0,
+			want: []string{
+				"rate limit exhausted, waiting 0s until it resets",
+				"created v0.0.2",
+				"rate limit exhausted, waiting 0s until it resets",
+				"rate limit exhausted, waiting 0s until it resets",
+				"created v0.0.4",
+				"created 2 releases, skipped 2 existing out of 4 tags",
+			},
+			wantPosts: []string{"v0.0.2", "v0.0.3", "v0.0.4"},
+		},
+		"dry run": {
+			dryRun: true,
+			want: []string{
+				`would create v0.0.2 (1 commits since "v0.0.1")`,
+				`would create v0.0.3 (1 commits since "v0.0.2")`,
+				`would create v0.0.4 (1 commits since "v0.0.3")`,
+				"would create 3 releases, skipped 1 existing out of 4 tags",
+			},
+		},
+	}
+	for name, tc := range tcs {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			var (
+				mu    sync.Mutex
+				posts []string
+			)
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch {
+				case r.URL.Path == "/rate_limit":
+					reset := time.Now().Add(-time.Minute).Unix()
+					fmt.Fprintf(w, `{"resources": {"core": {"limit": 5000, "remaining": %d, "reset": %d}}}`, tc.remaining, reset)
+				case r.URL.Path == "/repos/arsham/gitrelease/releases" && r.Method == http.MethodGet:
+					fmt.Fprint(w, `[{"id": 1, "tag_name": "v0.0.1"}]`)
+				case r.URL.Path == "/repos/arsham/gitrelease/releases" && r.Method == http.MethodPost:
+					var got map[string]interface{}
+					assert.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+					tag, _ := got["tag_name"].(string)
+					mu.Lock()
+					posts = append(posts, tag)
+					mu.Unlock()
+					if tag == "v0.0.3" {
+						w.WriteHeader(http.StatusUnprocessableEntity)
+						fmt.Fprint(w, `{"message": "Validation Failed", "errors": [{"resource": "Release", "field": "tag_name", "code": "already_exists"}]}`)
+						return
+					}
+					w.WriteHeader(http.StatusCreated)
+					fmt.Fprint(w, `{}`)
+				default:
+					t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+					w.WriteHeader(http.StatusNotFound)
+				}
+			}))
+			t.Cleanup(srv.Close)
+
+			out := &bytes.Buffer{}
+			b := commit.Backfiller{
+				Git:    &commit.Git{Dir: dir, BaseURL: srv.URL},
+				Token:  "token",
+				User:   "arsham",
+				Repo:   "gitrelease",
+				DryRun: tc.dryRun,
+				Out:    out,
+			}
+			require.NoError(t, b.Backfill(context.Background()))
+			got := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+			assert.Equal(t, tc.want, got)
+			assert.Equal(t, tc.wantPosts, posts)
+		})
+	}
+}
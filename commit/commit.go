@@ -3,11 +3,12 @@ package commit
 import (
 	"fmt"
 	"regexp"
+	"sort"
 	"strings"
 )
 
 var (
-	descRe = regexp.MustCompile(`^\s*([[:alpha:]]+!?)\(?([[:alpha:],_-]+)?\)?(!)?:?(.*)`)
+	descRe = regexp.MustCompile(`^\s*([[:alpha:]]+!?)\(?([[:alnum:],_-]+)?\)?(!)?:?(.*)`)
 	refRe  = regexp.MustCompile(`[[:alpha:]]+\s+#\d+`)
 )
 
@@ -21,11 +22,20 @@ type Group struct {
 	Subject     string
 	Description string
 	Breaking    bool
+	// Trailers are the trailers of the commit, e.g. Co-authored-by.
+	Trailers []Trailer
 }
 
 // GroupFromCommit creates a Group object from the given line.
 func GroupFromCommit(msg string) Group {
 	matches := descRe.FindStringSubmatch(msg)
+	if matches == nil {
+		return Group{
+			raw:         msg,
+			Verb:        "Misc",
+			Description: strings.TrimSpace(msg),
+		}
+	}
 	verb := matches[1]
 	subject := matches[2]
 	verbBreak := matches[3]
@@ -84,19 +94,16 @@ func (g Group) Section() string {
 // DescriptionString returns a string that is suitable for printing a line in a
 // Group.
 func (g Group) DescriptionString() string {
-	subject := g.Subject
-	if strings.EqualFold(subject, "ci") {
-		subject = "CI"
-	}
+	subject := formatSubject(g.Subject)
 	if subject != "" {
-		subjects := strings.Split(subject, ",")
-		for i := range subjects {
-			subjects[i] = upperFirst(subjects[i])
-		}
-		subject = strings.Join(subjects, ",")
 		subject = "**" + subject + ":** "
 	}
+	return fmt.Sprintf("- %s%s", subject, g.Text())
+}
 
+// Text returns the description of the group with the issue references found
+// in its body, without the scope.
+func (g Group) Text() string {
 	lines := strings.Split(g.Description, `\n`)
 	refs := make([]string, 0, len(lines))
 	title := lines[0]
@@ -115,31 +122,132 @@ func (g Group) DescriptionString() string {
 	if len(refs) > 0 {
 		ref = fmt.Sprintf(" (%s)", strings.Join(refs, ", "))
 	}
-	return fmt.Sprintf("- %s%s%s", subject, upperFirst(title), ref)
+	return upperFirst(title) + ref
 }
 
-// ParseGroups parses the lines in the logs and returns them as a string.
-func ParseGroups(logs []string) string {
-	logs = cleanup(logs)
-	groups := make(map[string][]Group, len(logs))
-	for _, line := range logs {
-		group := GroupFromCommit(line)
-		groups[group.Verb] = append(groups[group.Verb], group)
+// Scope returns the printable form of the scopes of the group.
+func (g Group) Scope() string {
+	return formatSubject(g.Subject)
+}
+
+// formatSubject returns the printable form of the comma separated scopes.
+func formatSubject(subject string) string {
+	if strings.EqualFold(subject, "ci") {
+		return "CI"
+	}
+	if subject == "" {
+		return ""
 	}
+	subjects := strings.Split(subject, ",")
+	for i := range subjects {
+		subjects[i] = upperFirst(subjects[i])
+	}
+	return strings.Join(subjects, ",")
+}
+
+// An Option changes how ParseGroups renders the notes.
+type Option func(*options)
+
+type options struct {
+	convention   Convention
+	tracker      *IssueTracker
+	scopeAliases map[string]string
+	byScope      bool
+	// trailers are the lowercase keys of the trailers listed in the entries.
+	// The trailers referencing issues are listed if it is nil.
+	trailers map[string]struct{}
+}
+
+// WithConvention parses the commit messages with the convention. The default
+// is ConventionalCommits.
+func WithConvention(c Convention) Option {
+	return func(o *options) {
+		o.convention = c
+	}
+}
+
+// WithScopeSections subdivides each section by the scopes of the entries.
+// Entries without a scope are listed first.
+func WithScopeSections() Option {
+	return func(o *options) {
+		o.byScope = true
+	}
+}
+
+// WithScopeAliases renames the scopes found as the keys of the aliases to
+// their values. Scopes can be merged by giving them the same alias. The keys
+// are matched case-insensitively.
+func WithScopeAliases(aliases map[string]string) Option {
+	return func(o *options) {
+		o.scopeAliases = make(map[string]string, len(aliases))
+		for k, v := range aliases {
+			o.scopeAliases[strings.ToLower(k)] = v
+		}
+	}
+}
+
+// WithTrailers lists the trailers with the keys in the entries, e.g. "Refs" or
+// "Co-authored-by". The keys are matched case-insensitively, and the email
+// addresses are left out of the values. By default, only the trailers with
+// issue references, e.g. "Refs: #12", are listed.
+func WithTrailers(keys ...string) Option {
+	return func(o *options) {
+		o.trailers = make(map[string]struct{}, len(keys))
+		for _, k := range keys {
+			o.trailers[strings.ToLower(k)] = struct{}{}
+		}
+	}
+}
+
+// showTrailer returns true if the trailer is listed in the entries.
+func (o *options) showTrailer(t Trailer) bool {
+	if o.trailers == nil {
+		return strings.Contains(t.Value, "#")
+	}
+	_, ok := o.trailers[strings.ToLower(t.Key)]
+	return ok
+}
+
+// scope returns the subject after renaming its scopes.
+func (o *options) scope(subject string) string {
+	if subject == "" || len(o.scopeAliases) == 0 {
+		return subject
+	}
+	scopes := strings.Split(subject, ",")
+	ret := make([]string, 0, len(scopes))
+	seen := make(map[string]struct{}, len(scopes))
+	for _, scope := range scopes {
+		if alias, ok := o.scopeAliases[strings.ToLower(scope)]; ok {
+			scope = alias
+		}
+		if _, ok := seen[scope]; ok {
+			continue
+		}
+		seen[scope] = struct{}{}
+		ret = append(ret, scope)
+	}
+	return strings.Join(ret, ",")
+}
+
+// ParseGroups parses the messages of the commits and returns them as a string.
+// The commits should be ordered from the newest to the oldest. The reverted
+// commits and their reverts are left out, and the reverts of the commits that
+// are not in the list are listed in the RevertVerb section.
+func ParseGroups(commits []Commit, opts ...Option) string {
+	o := newOptions(opts)
+	groups := o.groups(commits)
 
 	buf := &strings.Builder{}
-	i := 0
-	for _, desc := range groups {
+	verbs := sections(groups)
+	for i, verb := range verbs {
+		desc := groups[verb]
 		fmt.Fprintln(buf, desc[0].Section()+"\n")
-		for _, line := range desc {
-			fmt.Fprint(buf, line.DescriptionString())
-			if line.Breaking {
-				fmt.Fprintf(buf, " [**BREAKING CHANGE**]")
-			}
-			fmt.Fprintln(buf, "")
+		if o.byScope {
+			writeScopeEntries(buf, desc)
+		} else {
+			writeEntries(buf, desc)
 		}
-		i++
-		if i < len(groups) {
+		if i < len(verbs)-1 {
 			fmt.Fprintf(buf, "\n\n")
 		}
 	}
@@ -148,32 +256,173 @@ func ParseGroups(logs []string) string {
 	return strings.TrimSuffix(str, "\n")
 }
 
-// cleanup returns only the title of the logs.
-func cleanup(logs []string) []string {
-	ret := make([]string, 0, len(logs))
-	for _, commit := range logs {
-		items := strings.Split(commit, "\n")
-		item := items[0]
-		breaking := false
-		for _, line := range items[1:] {
-			if strings.Contains(line, "BREAKING CHANGE") {
-				breaking = true
+// Groups returns the groups ParseGroups lists in the release notes, in the
+// order of their sections. The groups in each section keep the order of the
+// commits.
+func Groups(commits []Commit, opts ...Option) []Group {
+	groups := newOptions(opts).groups(commits)
+	var ret []Group
+	for _, verb := range sections(groups) {
+		ret = append(ret, groups[verb]...)
+	}
+	return ret
+}
+
+// sectionOrder is the order of the known sections in the notes. The other
+// sections are listed alphabetically after them, followed by the lastSections.
+var (
+	sectionOrder = []string{BreakingVerb, "Refactor", "Feature", "Fix", "Chore", "Enhancements", "Upgrades", "CI", "Style", "Docs"}
+	lastSections = []string{"Misc", RevertVerb, TicketsVerb}
+)
+
+// sections returns the names of the sections of the groups in the order they
+// are listed in the notes.
+func sections(groups map[string][]Group) []string {
+	rank := func(verb string) int {
+		for i, v := range sectionOrder {
+			if v == verb {
+				return i
 			}
-			if !strings.Contains(line, "#") {
-				continue
+		}
+		for i, v := range lastSections {
+			if v == verb {
+				return len(sectionOrder) + 1 + i
 			}
-			item = fmt.Sprintf("%s (%s)", item, line)
 		}
-		if breaking {
-			item += " [**BREAKING CHANGE**]"
+		return len(sectionOrder)
+	}
+	verbs := make([]string, 0, len(groups))
+	for verb := range groups {
+		verbs = append(verbs, verb)
+	}
+	sort.Slice(verbs, func(i, j int) bool {
+		ri, rj := rank(verbs[i]), rank(verbs[j])
+		if ri != rj {
+			return ri < rj
 		}
-		if item == "" {
+		return verbs[i] < verbs[j]
+	})
+	return verbs
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{
+		convention: ConventionalCommits{},
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// groups returns the groups of the commits by their sections.
+func (o *options) groups(commits []Commit) map[string][]Group {
+	commits, reverts := dropReverts(commits)
+	groups := make(map[string][]Group, len(commits))
+	var keys []string
+	for _, c := range commits {
+		line, trailers := cleanup(c.Message, o.showTrailer)
+		if line == "" {
 			continue
 		}
-		item = strings.TrimPrefix(item, " ")
-		ret = append(ret, item)
+		group := o.convention.Group(line)
+		group.Trailers = trailers
+		if o.tracker != nil {
+			keys = append(keys, o.tracker.apply(&group, c.Message)...)
+		}
+		group.Subject = o.scope(group.Subject)
+		groups[group.Verb] = append(groups[group.Verb], group)
 	}
-	return ret
+	if len(reverts) > 0 {
+		groups[RevertVerb] = reverts
+	}
+	if o.tracker != nil && o.tracker.Summary && len(keys) > 0 {
+		groups[TicketsVerb] = o.tracker.summary(keys)
+	}
+	return groups
+}
+
+func writeEntries(buf *strings.Builder, groups []Group) {
+	for _, line := range groups {
+		fmt.Fprint(buf, line.DescriptionString())
+		if line.Breaking {
+			fmt.Fprintf(buf, " [**BREAKING CHANGE**]")
+		}
+		fmt.Fprintln(buf, "")
+	}
+}
+
+// writeScopeEntries writes the entries without a scope first, and then the
+// rest under a sub-section for each scope.
+func writeScopeEntries(buf *strings.Builder, groups []Group) {
+	var plain []Group
+	scoped := make(map[string][]Group)
+	for _, g := range groups {
+		if g.Subject == "" {
+			plain = append(plain, g)
+			continue
+		}
+		scope := formatSubject(g.Subject)
+		g.Subject = ""
+		scoped[scope] = append(scoped[scope], g)
+	}
+	writeEntries(buf, plain)
+
+	scopes := make([]string, 0, len(scoped))
+	for scope := range scoped {
+		scopes = append(scopes, scope)
+	}
+	sort.Strings(scopes)
+	for i, scope := range scopes {
+		if i > 0 || len(plain) > 0 {
+			fmt.Fprintln(buf, "")
+		}
+		fmt.Fprintf(buf, "#### %s\n\n", scope)
+		writeEntries(buf, scoped[scope])
+	}
+}
+
+// cleanup returns only the title of the commit, with the issue references in
+// its body, the trailers that show returns true for, and the breaking change
+// marker appended. It also returns all trailers of the commit.
+func cleanup(commit string, show func(Trailer) bool) (string, []Trailer) {
+	body, trailers := splitTrailers(commit)
+	items := strings.Split(body, "\n")
+	item := items[0]
+	breaking := false
+	for _, line := range strings.Split(commit, "\n")[1:] {
+		if strings.Contains(line, "BREAKING CHANGE") {
+			breaking = true
+		}
+	}
+	for _, line := range items[1:] {
+		if refs := issueRefs(line); len(refs) > 0 {
+			item = fmt.Sprintf("%s (%s)", item, strings.Join(refs, ", "))
+		}
+	}
+	for _, t := range trailers {
+		if show(t) {
+			item = fmt.Sprintf("%s (%s)", item, t)
+		}
+	}
+	if breaking {
+		item += " [**BREAKING CHANGE**]"
+	}
+	return strings.TrimPrefix(item, " "), trailers
+}
+
+// issueRefs returns the issue references in the line, e.g. "Fixes #12". The
+// bare references like "#12" are returned when the line has none with a verb.
+func issueRefs(line string) []string {
+	if refs := refRe.FindAllString(line, -1); len(refs) > 0 {
+		return refs
+	}
+	matches := refNumRe.FindAllStringSubmatch(line, -1)
+	refs := make([]string, 0, len(matches))
+	for _, m := range matches {
+		refs = append(refs, "#"+m[1])
+	}
+	return refs
 }
 
 // upperFirst makes the first letter of the string an uppercase letter.
@@ -22,6 +22,10 @@ func TestGroupFromCommit(t *testing.T) {
 			line: "something",
 			want: commit.NewGroup("Misc", "", "something", false),
 		},
+		"no verb": {
+			line: "123 something",
+			want: commit.NewGroup("Misc", "", "123 something", false),
+		},
 		"not special titled": {
 			line: "Something",
 			want: commit.NewGroup("Misc", "", "Something", false),
@@ -69,6 +73,7 @@ func TestGroupFromCommit(t *testing.T) {
 		"ci":           {line: "ci: change something", want: commit.NewGroup("CI", "", "change something", false)},
 		"comma sep":    {line: "fix(git,commit): something", want: commit.NewGroup("Fix", "git,commit", "something", false)},
 		"hyphen subj":  {line: "fix(git-commit): something", want: commit.NewGroup("Fix", "git-commit", "something", false)},
+		"digits subj":  {line: "fix(v2): something", want: commit.NewGroup("Fix", "v2", "something", false)},
 		"underscore":   {line: "fix(git_commit): something", want: commit.NewGroup("Fix", "git_commit", "something", false)},
 		"docs":         {line: "docs: change something", want: commit.NewGroup("Docs", "", "change something", false)},
 	}
@@ -89,6 +94,7 @@ func TestGroup(t *testing.T) {
 	t.Parallel()
 	t.Run("DescriptionString", testGroupDescriptionString)
 	t.Run("ParseGroups", testGroupParseGroups)
+	t.Run("Groups", testGroupGroups)
 }
 
 func testGroupDescriptionString(t *testing.T) {
@@ -164,12 +170,50 @@ func testGroupParseGroups(t *testing.T) {
 	t.Run("MultipleGroups", testGroupParseGroupsMultipleGroups)
 	t.Run("BreakingSign", testGroupParseGroupsBreakingSign)
 	t.Run("BreakingFooter", testGroupParseGroupsBreakingFooter)
+	t.Run("Reverts", testGroupParseGroupsReverts)
+	t.Run("RevertsByHash", testGroupParseGroupsRevertsByHash)
+	t.Run("ScopeSections", testGroupParseGroupsScopeSections)
+	t.Run("ScopeAliases", testGroupParseGroupsScopeAliases)
+	t.Run("Trailers", testGroupParseGroupsTrailers)
+	t.Run("Order", testGroupParseGroupsOrder)
+}
+
+func testGroupParseGroupsOrder(t *testing.T) {
+	t.Parallel()
+	logs := []string{
+		"docs: ninth",
+		"misc: tenth",
+		"style: eighth",
+		"ci: seventh",
+		"upgrade: sixth",
+		"enhance: fifth",
+		"chore: fourth",
+		"fix: third",
+		"feat: second",
+		"ref: first",
+	}
+	want := commit.ParseGroups(toCommits(logs))
+	var sections []string
+	for _, line := range strings.Split(want, "\n") {
+		if strings.HasPrefix(line, "### ") {
+			sections = append(sections, strings.TrimPrefix(line, "### "))
+		}
+	}
+	wantSections := []string{"Refactor", "Feature", "Fix", "Chore", "Enhancements", "Upgrades", "CI", "Style", "Docs", "Misc"}
+	if diff := cmp.Diff(wantSections, sections); diff != "" {
+		t.Errorf("(-want +got):\n%s", diff)
+	}
+	for i := 0; i < 20; i++ {
+		if got := commit.ParseGroups(toCommits(logs)); got != want {
+			t.Fatalf("run %d: got:\n%s\nwant:\n%s", i, got, want)
+		}
+	}
 }
 
 func testGroupParseGroupsOneGroup(t *testing.T) {
 	t.Parallel()
 	logs := []string{"Feat(testing): this is a test"}
-	got := commit.ParseGroups(logs)
+	got := commit.ParseGroups(toCommits(logs))
 
 	got = strings.TrimRight(got, "\n")
 	want := "### Feature\n\n- **Testing:** This is a test"
@@ -185,7 +229,7 @@ func testGroupParseGroupsMultipleGroups(t *testing.T) {
 		"Misc: this is another test",
 		"feat: yet another",
 	}
-	got := commit.ParseGroups(logs)
+	got := commit.ParseGroups(toCommits(logs))
 
 	want := []string{
 		"### Feature\n\n- **Testing:** This is a test\n- Yet another",
@@ -210,7 +254,7 @@ func testGroupParseGroupsBreakingSignNoScope(t *testing.T) {
 		"ref: nothing important",
 		"ref!: this is a test",
 	}
-	got := commit.ParseGroups(logs)
+	got := commit.ParseGroups(toCommits(logs))
 
 	want := strings.Join([]string{
 		"### Refactor\n",
@@ -228,7 +272,7 @@ func testGroupParseGroupsBreakingSignBeforeScope(t *testing.T) {
 		"ref: nothing important",
 		"ref!(repo): this is a test",
 	}
-	got := commit.ParseGroups(logs)
+	got := commit.ParseGroups(toCommits(logs))
 
 	want := strings.Join([]string{
 		"### Refactor\n",
@@ -246,7 +290,7 @@ func testGroupParseGroupsBreakingSignAfterScope(t *testing.T) {
 		"ref: nothing important",
 		"ref(repo)!: this is a test",
 	}
-	got := commit.ParseGroups(logs)
+	got := commit.ParseGroups(toCommits(logs))
 
 	want := strings.Join([]string{
 		"### Refactor\n",
@@ -264,7 +308,7 @@ func testGroupParseGroupsBreakingFooter(t *testing.T) {
 		"ref(server): nothing special",
 		"ref(repo): this is a new api\n\nBREAKING CHANGE: this is a changed api",
 	}
-	got := commit.ParseGroups(logs)
+	got := commit.ParseGroups(toCommits(logs))
 
 	want := strings.Join([]string{
 		"### Refactor\n",
@@ -275,3 +319,272 @@ func testGroupParseGroupsBreakingFooter(t *testing.T) {
 		t.Errorf("(-want +got):\n%s", diff)
 	}
 }
+
+func testGroupParseGroupsReverts(t *testing.T) {
+	t.Parallel()
+	tcs := map[string]struct {
+		logs []string
+		want string
+	}{
+		"matched pair": {
+			logs: []string{
+				"Revert \"feat: add x\"\n\nThis reverts commit 0123456789abcdef.",
+				"fix: something",
+				"feat: add x",
+			},
+			want: "### Fix\n\n- Something",
+		},
+		"reverted twice": {
+			logs: []string{
+				"Revert \"feat: add x\"\n\nThis reverts commit 0123456789abcdef.",
+				"feat: add x",
+				"Revert \"feat: add x\"\n\nThis reverts commit 0123456789abcdef.",
+				"feat: add x",
+			},
+			want: "",
+		},
+		"revert of revert": {
+			logs: []string{
+				"Revert \"Revert \"feat: add x\"\"\n\nThis reverts commit 0123456789abcdef.",
+				"Revert \"feat: add x\"\n\nThis reverts commit 0123456789abcdef.",
+				"feat: add x",
+			},
+			want: "### Feature\n\n- Add x",
+		},
+		"unmatched": {
+			logs: []string{
+				"Revert \"feat: add x\"\n\nThis reverts commit 0123456789abcdef.",
+				"Revert \"feat: add y\"",
+			},
+			want: "### Reverts\n\n- Feat: add x\n- Feat: add y",
+		},
+		"body only": {
+			logs: []string{
+				"undo the change\n\nThis reverts commit 0123456789abcdef.",
+			},
+			want: "### Reverts\n\n- Undo the change",
+		},
+		"revert before commit": {
+			logs: []string{
+				"feat: add x",
+				"Revert \"feat: add x\"",
+			},
+			want: "### Feature\n\n- Add x\n\n\n### Reverts\n\n- Feat: add x",
+		},
+	}
+
+	for name, tc := range tcs {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			got := commit.ParseGroups(toCommits(tc.logs))
+			wantS := strings.Split(tc.want, "\n\n\n")
+			gotS := strings.Split(got, "\n\n\n")
+			sort.Strings(gotS)
+			if diff := cmp.Diff(wantS, gotS); diff != "" {
+				t.Errorf("(-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func testGroupParseGroupsRevertsByHash(t *testing.T) {
+	t.Parallel()
+	feat := commit.Commit{Hash: "0123456789abcdef0123456789abcdef01234567", Message: "feat: add x"}
+	fix := commit.Commit{Hash: "89abcdef0123456789abcdef0123456789abcdef", Message: "fix: something"}
+	revert := commit.Commit{
+		Hash:    "fedcba9876543210fedcba9876543210fedcba98",
+		Message: "undo x, it breaks the build\n\nThis reverts commit 0123456789abcdef.",
+	}
+	tcs := map[string]struct {
+		commits []commit.Commit
+		want    string
+	}{
+		"custom title": {
+			commits: []commit.Commit{revert, fix, feat},
+			want:    "### Fix\n\n- Something",
+		},
+		"other commit": {
+			commits: []commit.Commit{revert, fix},
+			want:    "### Fix\n\n- Something\n\n\n### Reverts\n\n- Undo x, it breaks the build",
+		},
+		"revert of revert": {
+			commits: []commit.Commit{
+				{Hash: "5555555555555555555555555555555555555555", Message: "bring x back\n\nThis reverts commit fedcba9876543."},
+				revert,
+				feat,
+			},
+			want: "### Feature\n\n- Add x",
+		},
+		"hash over title": {
+			commits: []commit.Commit{
+				{Hash: "5555555555555555555555555555555555555555", Message: "Revert \"fix: something\"\n\nThis reverts commit 0123456789abcdef."},
+				fix,
+				feat,
+			},
+			want: "### Fix\n\n- Something",
+		},
+	}
+	for name, tc := range tcs {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			got := commit.ParseGroups(tc.commits)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("(-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func testGroupParseGroupsScopeSections(t *testing.T) {
+	t.Parallel()
+	tcs := map[string]struct {
+		logs []string
+		want string
+	}{
+		"mixed": {
+			logs: []string{
+				"fix(cli): first",
+				"fix: second",
+				"fix(api): third",
+				"fix(cli)!: fourth",
+			},
+			want: strings.Join([]string{
+				"### Fix\n",
+				"- Second\n",
+				"#### Api\n",
+				"- Third\n",
+				"#### Cli\n",
+				"- First",
+				"- Fourth [**BREAKING CHANGE**]",
+			}, "\n"),
+		},
+		"only scopes": {
+			logs: []string{"fix(cli): first", "fix(api): second"},
+			want: "### Fix\n\n#### Api\n\n- Second\n\n#### Cli\n\n- First",
+		},
+		"no scopes": {
+			logs: []string{"fix: first", "fix: second"},
+			want: "### Fix\n\n- First\n- Second",
+		},
+	}
+
+	for name, tc := range tcs {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			got := commit.ParseGroups(toCommits(tc.logs), commit.WithScopeSections())
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("(-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func testGroupParseGroupsScopeAliases(t *testing.T) {
+	t.Parallel()
+	aliases := map[string]string{
+		"API-Server":  "api",
+		"api-client":  "api",
+		"commandline": "cli",
+	}
+	logs := []string{
+		"fix(api-server): first",
+		"fix(api-client,api): second",
+		"fix(commandline): third",
+	}
+
+	got := commit.ParseGroups(toCommits(logs), commit.WithScopeAliases(aliases))
+	want := "### Fix\n\n- **Api:** First\n- **Api:** Second\n- **Cli:** Third"
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("(-want +got):\n%s", diff)
+	}
+
+	got = commit.ParseGroups(toCommits(logs), commit.WithScopeAliases(aliases), commit.WithScopeSections())
+	want = "### Fix\n\n#### Api\n\n- First\n- Second\n\n#### Cli\n\n- Third"
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("(-want +got):\n%s", diff)
+	}
+}
+
+func testGroupGroups(t *testing.T) {
+	t.Parallel()
+	logs := []string{
+		"fix(api): first",
+		"feat: second\n\nCloses #12",
+		"fix: third",
+		"chore: fourth",
+	}
+
+	type entry struct {
+		Verb  string
+		Scope string
+		Text  string
+	}
+	var got []entry
+	for _, g := range commit.Groups(toCommits(logs)) {
+		got = append(got, entry{Verb: g.Verb, Scope: g.Scope(), Text: g.Text()})
+	}
+	want := []entry{
+		{Verb: "Feature", Text: "Second (Closes #12)"},
+		{Verb: "Fix", Scope: "Api", Text: "First"},
+		{Verb: "Fix", Text: "Third"},
+		{Verb: "Chore", Text: "Fourth"},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("(-want +got):\n%s", diff)
+	}
+}
+
+func testGroupParseGroupsTrailers(t *testing.T) {
+	t.Parallel()
+	logs := []string{
+		"feat: first\n\nIt has a #hashtag in the body (#14).\n\nCo-authored-by: John Doe <john@example.com>\nRefs: #12\nSigned-off-by: arsham <arsham@github.com>",
+		"fix: second\n\nFixes #13\n\nBREAKING CHANGE: it changes x\nReviewed-by: Jane <jane@example.com>",
+	}
+	tcs := map[string]struct {
+		opts []commit.Option
+		want string
+	}{
+		"default": {
+			want: "### Feature\n\n- First (#14) (Refs: #12)\n\n\n### Fix\n\n- Second (Fixes #13) [**BREAKING CHANGE**]",
+		},
+		"co-authors": {
+			opts: []commit.Option{commit.WithTrailers("co-authored-by")},
+			want: "### Feature\n\n- First (#14) (Co-authored-by: John Doe)\n\n\n### Fix\n\n- Second (Fixes #13) [**BREAKING CHANGE**]",
+		},
+		"none": {
+			opts: []commit.Option{commit.WithTrailers()},
+			want: "### Feature\n\n- First (#14)\n\n\n### Fix\n\n- Second (Fixes #13) [**BREAKING CHANGE**]",
+		},
+	}
+	for name, tc := range tcs {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			got := commit.ParseGroups(toCommits(logs), tc.opts...)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("(-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	groups := commit.Groups(toCommits(logs))
+	if len(groups) != 2 {
+		t.Fatalf("got %d groups, want 2", len(groups))
+	}
+	want := []commit.Trailer{
+		{Key: "Co-authored-by", Value: "John Doe <john@example.com>"},
+		{Key: "Refs", Value: "#12"},
+		{Key: "Signed-off-by", Value: "arsham <arsham@github.com>"},
+	}
+	if diff := cmp.Diff(want, groups[0].Trailers); diff != "" {
+		t.Errorf("(-want +got):\n%s", diff)
+	}
+	// The breaking change footer is not a trailer, so the paragraph is not a
+	// trailer block.
+	if len(groups[1].Trailers) != 0 {
+		t.Errorf("got %v, want no trailers", groups[1].Trailers)
+	}
+}
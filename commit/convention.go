@@ -0,0 +1,176 @@
+package commit
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// A Convention turns commit messages into Groups.
+type Convention interface {
+	Group(msg string) Group
+}
+
+// ConventionalCommits parses the messages following the Conventional Commits
+// specification, e.g. "feat(api): add x". This is the default convention.
+type ConventionalCommits struct{}
+
+// Group returns the Group of the message.
+func (ConventionalCommits) Group(msg string) Group {
+	return GroupFromCommit(msg)
+}
+
+// Gitmoji parses the messages starting with an emoji or its shortcode, as
+// described in https://gitmoji.dev, e.g. ":sparkles: add x" or "✨ (api): add
+// x".
+type Gitmoji struct{}
+
+// BreakingVerb is the section the gitmoji commits with the :boom: emoji are
+// listed in.
+const BreakingVerb = "Breaking Changes"
+
+// gitmojis maps the gitmoji shortcodes to the sections their commits are
+// listed in.
+var gitmojis = map[string]string{
+	"sparkles":             "Feature",
+	"tada":                 "Feature",
+	"bug":                  "Fix",
+	"ambulance":            "Fix",
+	"adhesive_bandage":     "Fix",
+	"lock":                 "Fix",
+	"pencil2":              "Fix",
+	"recycle":              "Refactor",
+	"truck":                "Refactor",
+	"fire":                 "Refactor",
+	"coffin":               "Refactor",
+	"zap":                  "Enhancements",
+	"globe_with_meridians": "Enhancements",
+	"wheelchair":           "Enhancements",
+	"children_crossing":    "Enhancements",
+	"art":                  "Style",
+	"lipstick":             "Style",
+	"memo":                 "Docs",
+	"bulb":                 "Docs",
+	"construction_worker":  "CI",
+	"green_heart":          "CI",
+	"rocket":               "CI",
+	"arrow_up":             "Upgrades",
+	"arrow_down":           "Upgrades",
+	"pushpin":              "Upgrades",
+	"heavy_plus_sign":      "Upgrades",
+	"heavy_minus_sign":     "Upgrades",
+	"wrench":               "Chore",
+	"hammer":               "Chore",
+	"see_no_evil":          "Chore",
+	"bookmark":             "Chore",
+	"white_check_mark":     "Chore",
+	"rotating_light":       "Chore",
+	"boom":                 BreakingVerb,
+	"rewind":               RevertVerb,
+}
+
+// emojiShortcodes maps the gitmoji emojis, without the variation selectors, to
+// their shortcodes.
+var emojiShortcodes = map[string]string{
+	"✨": "sparkles",
+	"🎉": "tada",
+	"🐛": "bug",
+	"🚑": "ambulance",
+	"🩹": "adhesive_bandage",
+	"🔒": "lock",
+	"✏": "pencil2",
+	"♻": "recycle",
+	"🚚": "truck",
+	"🔥": "fire",
+	"⚰": "coffin",
+	"⚡": "zap",
+	"🌐": "globe_with_meridians",
+	"♿": "wheelchair",
+	"🚸": "children_crossing",
+	"🎨": "art",
+	"💄": "lipstick",
+	"📝": "memo",
+	"💡": "bulb",
+	"👷": "construction_worker",
+	"💚": "green_heart",
+	"🚀": "rocket",
+	"⬆": "arrow_up",
+	"⬇": "arrow_down",
+	"📌": "pushpin",
+	"➕": "heavy_plus_sign",
+	"➖": "heavy_minus_sign",
+	"🔧": "wrench",
+	"🔨": "hammer",
+	"🙈": "see_no_evil",
+	"🔖": "bookmark",
+	"✅": "white_check_mark",
+	"🚨": "rotating_light",
+	"💥": "boom",
+	"⏪": "rewind",
+}
+
+var (
+	shortcodeRe    = regexp.MustCompile(`^:([a-z0-9_+-]+):`)
+	gitmojiScopeRe = regexp.MustCompile(`^\s*(?:\(([[:alnum:],_-]+)\))?:?(.*)`)
+)
+
+// Group returns the Group of the message. Messages without a known gitmoji are
+// listed under Misc.
+func (Gitmoji) Group(msg string) Group {
+	title := strings.TrimSpace(msg)
+	misc := Group{
+		raw:         msg,
+		Verb:        "Misc",
+		Description: title,
+	}
+
+	var code, rest string
+	if m := shortcodeRe.FindStringSubmatch(title); m != nil {
+		code = m[1]
+		rest = title[len(m[0]):]
+	} else {
+		emoji, size := leadingEmoji(title)
+		code = emojiShortcodes[emoji]
+		rest = title[size:]
+	}
+	verb, ok := gitmojis[code]
+	if !ok {
+		return misc
+	}
+
+	matches := gitmojiScopeRe.FindStringSubmatch(rest)
+	return Group{
+		raw:         msg,
+		Verb:        verb,
+		Subject:     matches[1],
+		Description: strings.TrimSpace(matches[2]),
+		Breaking:    verb == BreakingVerb,
+	}
+}
+
+// leadingEmoji returns the first emoji of the string without its variation
+// selector, and the number of bytes it takes including the selector.
+func leadingEmoji(s string) (string, int) {
+	r, size := utf8.DecodeRuneInString(s)
+	if r == utf8.RuneError {
+		return "", 0
+	}
+	emoji := string(r)
+	if next, n := utf8.DecodeRuneInString(s[size:]); next == '\ufe0f' {
+		size += n
+	}
+	return emoji, size
+}
+
+// ConventionByName returns the convention with the name. An empty name
+// results in ConventionalCommits.
+func ConventionByName(name string) (Convention, error) {
+	switch strings.ToLower(name) {
+	case "", "conventional":
+		return ConventionalCommits{}, nil
+	case "gitmoji":
+		return Gitmoji{}, nil
+	}
+	return nil, fmt.Errorf("unknown commit convention %q, valid values are: conventional, gitmoji", name)
+}
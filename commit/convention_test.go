@@ -0,0 +1,98 @@
+package commit_test
+
+import (
+	"testing"
+
+	"github.com/arsham/gitrelease/commit"
+	"github.com/google/go-cmp/cmp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGitmoji(t *testing.T) {
+	t.Parallel()
+	t.Run("Group", testGitmojiGroup)
+	t.Run("ParseGroups", testGitmojiParseGroups)
+	t.Run("Lint", testGitmojiLint)
+}
+
+func testGitmojiGroup(t *testing.T) {
+	t.Parallel()
+	tcs := map[string]struct {
+		line string
+		want commit.Group
+	}{
+		"shortcode":         {line: ":sparkles: add x", want: commit.NewGroup("Feature", "", "add x", false)},
+		"emoji":             {line: "✨ add x", want: commit.NewGroup("Feature", "", "add x", false)},
+		"emoji no space":    {line: "🐛fix x", want: commit.NewGroup("Fix", "", "fix x", false)},
+		"variation":         {line: "♻️ move x", want: commit.NewGroup("Refactor", "", "move x", false)},
+		"no variation":      {line: "♻ move x", want: commit.NewGroup("Refactor", "", "move x", false)},
+		"scope":             {line: ":bug: (api): fix x", want: commit.NewGroup("Fix", "api", "fix x", false)},
+		"emoji scope":       {line: "📝 (readme) update", want: commit.NewGroup("Docs", "readme", "update", false)},
+		"ticket scope":      {line: ":bug: (PLAT-12): fix x", want: commit.NewGroup("Fix", "PLAT-12", "fix x", false)},
+		"breaking":          {line: ":boom: (api): drop x", want: commit.NewGroup(commit.BreakingVerb, "api", "drop x", true)},
+		"breaking emoji":    {line: "💥 drop x", want: commit.NewGroup(commit.BreakingVerb, "", "drop x", true)},
+		"unknown shortcode": {line: ":unicorn: add x", want: commit.NewGroup("Misc", "", ":unicorn: add x", false)},
+		"unknown emoji":     {line: "🦄 add x", want: commit.NewGroup("Misc", "", "🦄 add x", false)},
+		"conventional":      {line: "feat: add x", want: commit.NewGroup("Misc", "", "feat: add x", false)},
+	}
+
+	for name, tc := range tcs {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			got := commit.Gitmoji{}.Group(tc.line)
+			if diff := cmp.Diff(tc.want, got, commit.GroupComparer...); diff != "" {
+				t.Errorf("(-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func testGitmojiParseGroups(t *testing.T) {
+	t.Parallel()
+	logs := []string{
+		":sparkles: (api): add x",
+		"✨ add y\n\nClose #12",
+		":memo: document x",
+		":boom: drop z",
+	}
+	got := commit.ParseGroups(toCommits(logs), commit.WithConvention(commit.Gitmoji{}))
+	want := "### Breaking Changes\n\n- Drop z [**BREAKING CHANGE**]\n\n\n" +
+		"### Feature\n\n- **Api:** Add x\n- Add y (Close #12)\n\n\n" +
+		"### Docs\n\n- Document x"
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("(-want +got):\n%s", diff)
+	}
+}
+
+func testGitmojiLint(t *testing.T) {
+	t.Parallel()
+	linter := commit.Linter{
+		Convention: commit.Gitmoji{},
+		Scopes:     []string{"api"},
+	}
+	assert.Empty(t, linter.Lint(":sparkles: (api): add x"))
+	assert.Equal(t, []commit.Diagnostic{{Line: 1, Message: "unknown convention prefix, it will be listed under Misc"}},
+		linter.Lint("feat: add x"))
+	assert.Equal(t, []commit.Diagnostic{{Line: 1, Message: `scope "cli" is not allowed`}},
+		linter.Lint("✨ (cli): add x"))
+}
+
+func TestConventionByName(t *testing.T) {
+	t.Parallel()
+	tcs := map[string]commit.Convention{
+		"":             commit.ConventionalCommits{},
+		"conventional": commit.ConventionalCommits{},
+		"gitmoji":      commit.Gitmoji{},
+		"Gitmoji":      commit.Gitmoji{},
+	}
+	for name, want := range tcs {
+		got, err := commit.ConventionByName(name)
+		require.NoError(t, err, name)
+		assert.Equal(t, want, got, name)
+	}
+
+	_, err := commit.ConventionByName("angular")
+	assert.Error(t, err)
+}
@@ -0,0 +1,147 @@
+package commit
+
+import (
+	"fmt"
+	"strings"
+)
+
+// diffContext is the number of unchanged lines around the changes in the
+// hunks of the unified diff.
+const diffContext = 3
+
+type diffLine struct {
+	// op is ' ' for the unchanged lines, '-' for the removed and '+' for the
+	// added lines.
+	op   byte
+	text string
+}
+
+// UnifiedDiff returns the differences between the from and to texts in the
+// unified format of diff -u, with the names in the headers of the files. It
+// returns an empty string if the texts are the same.
+func UnifiedDiff(fromName, toName, from, to string) string {
+	lines := diffLines(splitLines(from), splitLines(to))
+	var changes []int
+	for i, l := range lines {
+		if l.op != ' ' {
+			changes = append(changes, i)
+		}
+	}
+	if len(changes) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "--- %s\n+++ %s\n", fromName, toName)
+	for i := 0; i < len(changes); {
+		// The changes that are close enough to share their context are in the
+		// same hunk.
+		j := i
+		for j+1 < len(changes) && changes[j+1]-changes[j] <= 2*diffContext+1 {
+			j++
+		}
+		start := changes[i] - diffContext
+		if start < 0 {
+			start = 0
+		}
+		end := changes[j] + diffContext + 1
+		if end > len(lines) {
+			end = len(lines)
+		}
+		writeHunk(&b, lines, start, end)
+		i = j + 1
+	}
+	return b.String()
+}
+
+// writeHunk writes the lines from start to end with their header.
+func writeHunk(b *strings.Builder, lines []diffLine, start, end int) {
+	var fromStart, toStart, fromCount, toCount int
+	for i, l := range lines[:end] {
+		from, to := l.op != '+', l.op != '-'
+		switch {
+		case i < start:
+			if from {
+				fromStart++
+			}
+			if to {
+				toStart++
+			}
+		default:
+			if from {
+				fromCount++
+			}
+			if to {
+				toCount++
+			}
+		}
+	}
+	fmt.Fprintf(b, "@@ -%s +%s @@\n", hunkRange(fromStart, fromCount), hunkRange(toStart, toCount))
+	for _, l := range lines[start:end] {
+		b.WriteByte(l.op)
+		b.WriteString(l.text)
+		b.WriteByte('\n')
+	}
+}
+
+// hunkRange returns the range of the lines in the hunk header. The start is the
+// number of the lines before the hunk.
+func hunkRange(start, count int) string {
+	switch count {
+	case 0:
+		return fmt.Sprintf("%d,0", start)
+	case 1:
+		return fmt.Sprintf("%d", start+1)
+	}
+	return fmt.Sprintf("%d,%d", start+1, count)
+}
+
+// splitLines returns the lines of the text. An empty text has no lines.
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
+
+// diffLines returns the lines of a and b, marked by the longest common
+// subsequence of them. The removed lines come before the added ones in each
+// change.
+func diffLines(a, b []string) []diffLine {
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and
+	// b[j:].
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			switch {
+			case a[i] == b[j]:
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			case lcs[i+1][j] >= lcs[i][j+1]:
+				lcs[i][j] = lcs[i+1][j]
+			default:
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	ret := make([]diffLine, 0, len(a)+len(b))
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			ret = append(ret, diffLine{op: ' ', text: a[i]})
+			i++
+			j++
+		case j == len(b) || (i < len(a) && lcs[i+1][j] >= lcs[i][j+1]):
+			ret = append(ret, diffLine{op: '-', text: a[i]})
+			i++
+		default:
+			ret = append(ret, diffLine{op: '+', text: b[j]})
+			j++
+		}
+	}
+	return ret
+}
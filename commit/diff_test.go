@@ -0,0 +1,71 @@
+package commit_test
+
+import (
+	"testing"
+
+	"github.com/arsham/gitrelease/commit"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	t.Parallel()
+	tcs := map[string]struct {
+		from string
+		to   string
+		want string
+	}{
+		"same": {
+			from: "a\nb\n",
+			to:   "a\nb\n",
+			want: "",
+		},
+		"empty": {
+			from: "",
+			to:   "",
+			want: "",
+		},
+		"new": {
+			from: "",
+			to:   "### Fix\n\n- Something\n",
+			want: "--- current\n+++ updated\n@@ -0,0 +1,3 @@\n+### Fix\n+\n+- Something\n",
+		},
+		"removed": {
+			from: "a\n",
+			to:   "",
+			want: "--- current\n+++ updated\n@@ -1 +0,0 @@\n-a\n",
+		},
+		"changed line": {
+			from: "1\n2\n3\n4\n5\n",
+			to:   "1\n2\nthree\n4\n5\n",
+			want: "--- current\n+++ updated\n@@ -1,5 +1,5 @@\n 1\n 2\n-3\n+three\n 4\n 5\n",
+		},
+		"separate hunks": {
+			from: "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n",
+			to:   "one\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\ntwelve\n",
+			want: "--- current\n+++ updated\n" +
+				"@@ -1,4 +1,4 @@\n-1\n+one\n 2\n 3\n 4\n" +
+				"@@ -9,4 +9,4 @@\n 9\n 10\n 11\n-12\n+twelve\n",
+		},
+		"shared context": {
+			from: "1\n2\n3\n4\n5\n6\n7\n8\n",
+			to:   "one\n2\n3\n4\n5\n6\n7\neight\n",
+			want: "--- current\n+++ updated\n" +
+				"@@ -1,8 +1,8 @@\n-1\n+one\n 2\n 3\n 4\n 5\n 6\n 7\n-8\n+eight\n",
+		},
+		"added in the middle": {
+			from: "a\nb\nc\nd\ne\nf\ng\nh\n",
+			to:   "a\nb\nc\nd\nx\ne\nf\ng\nh\n",
+			want: "--- current\n+++ updated\n@@ -2,6 +2,7 @@\n b\n c\n d\n+x\n e\n f\n g\n",
+		},
+	}
+	for name, tc := range tcs {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			got := commit.UnifiedDiff("current", "updated", tc.from, tc.to)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("(-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,202 @@
+package commit
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	// ErrNotRepository is returned when the directory is not in a git
+	// repository.
+	ErrNotRepository = errors.New("not a git repository")
+	// ErrNoTags is returned when there are no tags to describe a commit, e.g.
+	// when asking for the previous tag of the first tag.
+	ErrNoTags = errors.New("no tags found")
+	// ErrUnknownRemote is returned when the remote is not configured.
+	ErrUnknownRemote = errors.New("unknown remote")
+	// ErrReleaseExists is returned when the tag already has a release.
+	ErrReleaseExists = errors.New("release already exists")
+	// ErrTagNotFound is returned when the tag or the revision doesn't exist in
+	// the repository, or on GitHub.
+	ErrTagNotFound = errors.New("tag not found")
+	// ErrUnauthorized is returned when the API rejects the token, e.g. because
+	// it is expired or revoked.
+	ErrUnauthorized = errors.New("bad credentials")
+	// ErrForbidden is returned when the token doesn't have the permission for
+	// the request. It is not returned for rate limits.
+	ErrForbidden = errors.New("permission denied")
+)
+
+// GitError is returned when reading the repository fails. It matches the
+// ErrNotRepository, ErrNoTags, ErrTagNotFound and ErrUnknownRemote errors with
+// errors.Is when the failure is one of them.
+type GitError struct {
+	// Op is the failed operation, e.g. "git describe".
+	Op string
+	// Output is the output of git, if it was run.
+	Output string
+	// Err is the cause of the failure.
+	Err error
+	// Kind is one of the sentinel errors, or nil if the failure is not
+	// recognised.
+	Kind error
+}
+
+func (e *GitError) Error() string {
+	msg := strings.TrimSpace(e.Output)
+	if msg == "" && e.Err != nil {
+		msg = e.Err.Error()
+	}
+	return fmt.Sprintf("%s: %s", e.Op, msg)
+}
+
+// Unwrap returns the cause of the failure.
+func (e *GitError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether the failure is the target error.
+func (e *GitError) Is(target error) bool {
+	return e.Kind != nil && e.Kind == target
+}
+
+// gitErrorKind returns the sentinel error described by the output of git, or
+// nil if it is not recognised.
+func gitErrorKind(out string) error {
+	switch {
+	case strings.Contains(out, "not a git repository"):
+		return ErrNotRepository
+	case strings.Contains(out, "No names found"), strings.Contains(out, "No tags can describe"):
+		return ErrNoTags
+	case strings.Contains(out, "Not a valid object name"),
+		strings.Contains(out, "unknown revision"),
+		strings.Contains(out, "bad revision"):
+		return ErrTagNotFound
+	}
+	return nil
+}
+
+// APIError is returned when the API responds with an error status code. It
+// matches the ErrReleaseExists, ErrTagNotFound, ErrUnauthorized and
+// ErrForbidden errors with errors.Is when the response describes them.
+type APIError struct {
+	// Status is the status line, e.g. "422 Unprocessable Entity".
+	Status string
+	Code   int
+	// Message is the message in the response, or the whole body if it is not
+	// a JSON document.
+	Message string
+	Errors  []FieldError
+	// DocumentationURL is the address of the documentation for the error.
+	DocumentationURL string
+	// RetryAfter is how long to wait before trying again when the request is
+	// RateLimited.
+	RetryAfter  time.Duration
+	RateLimited bool
+}
+
+// FieldError describes why a field of the request is not valid.
+type FieldError struct {
+	Resource string `json:"resource"`
+	Field    string `json:"field"`
+	// Code is the kind of the error, e.g. missing, invalid or already_exists.
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// UnmarshalJSON decodes the error, which can also be a plain string.
+func (f *FieldError) UnmarshalJSON(data []byte) error {
+	var msg string
+	if err := json.Unmarshal(data, &msg); err == nil {
+		*f = FieldError{Message: msg}
+		return nil
+	}
+	type plain FieldError
+	return json.Unmarshal(data, (*plain)(f))
+}
+
+func (f FieldError) String() string {
+	if f.Message != "" {
+		return f.Message
+	}
+	return strings.TrimSpace(fmt.Sprintf("%s %s %s", f.Resource, f.Field, f.Code))
+}
+
+func (e *APIError) Error() string {
+	msg := e.Message
+	if len(e.Errors) > 0 {
+		details := make([]string, 0, len(e.Errors))
+		for _, f := range e.Errors {
+			details = append(details, f.String())
+		}
+		msg = fmt.Sprintf("%s (%s)", msg, strings.Join(details, "; "))
+	}
+	if msg == "" {
+		return e.Status
+	}
+	return fmt.Sprintf("%s: %s", e.Status, msg)
+}
+
+// Is reports whether the response describes the target error.
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrUnauthorized:
+		return e.Code == http.StatusUnauthorized
+	case ErrForbidden:
+		return e.Code == http.StatusForbidden && !e.RateLimited
+	case ErrReleaseExists:
+		return e.hasField("tag_name", "already_exists")
+	case ErrTagNotFound:
+		return e.hasField("tag_name", "missing") || e.hasField("target_commitish", "invalid")
+	}
+	return false
+}
+
+// hasField returns true if the response is a validation error for the field
+// with the code.
+func (e *APIError) hasField(field, code string) bool {
+	if e.Code != http.StatusUnprocessableEntity {
+		return false
+	}
+	for _, f := range e.Errors {
+		if f.Field == field && f.Code == code {
+			return true
+		}
+	}
+	return false
+}
+
+func parseError(resp *http.Response) error {
+	// nolint:errcheck // it's ok.
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return errors.Wrap(err, "reading error response")
+	}
+	wait, limited := retryAfter(resp)
+	e := &APIError{
+		Status:      resp.Status,
+		Code:        resp.StatusCode,
+		RetryAfter:  wait,
+		RateLimited: limited,
+	}
+	var v struct {
+		Message          string       `json:"message"`
+		Errors           []FieldError `json:"errors"`
+		DocumentationURL string       `json:"documentation_url"`
+	}
+	if err := json.Unmarshal(body, &v); err != nil || v.Message == "" {
+		e.Message = strings.TrimSpace(string(body))
+		return e
+	}
+	e.Message = v.Message
+	e.Errors = v.Errors
+	e.DocumentationURL = v.DocumentationURL
+	return e
+}
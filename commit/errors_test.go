@@ -0,0 +1,91 @@
+package commit_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/arsham/gitrelease/commit"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPIError(t *testing.T) {
+	t.Parallel()
+	all := []error{commit.ErrReleaseExists, commit.ErrTagNotFound, commit.ErrUnauthorized, commit.ErrForbidden}
+	tcs := map[string]struct {
+		status  int
+		body    string
+		want    error
+		message string
+	}{
+		"release exists": {
+			status:  http.StatusUnprocessableEntity,
+			body:    `{"message": "Validation Failed", "errors": [{"resource": "Release", "code": "already_exists", "field": "tag_name"}]}`,
+			want:    commit.ErrReleaseExists,
+			message: "422 Unprocessable Entity: Validation Failed (Release tag_name already_exists)",
+		},
+		"invalid tag name": {
+			status:  http.StatusUnprocessableEntity,
+			body:    `{"message": "Validation Failed", "errors": [{"resource": "Release", "code": "custom", "field": "tag_name", "message": "tag_name is not a valid tag"}]}`,
+			message: "422 Unprocessable Entity: Validation Failed (tag_name is not a valid tag)",
+		},
+		"tag not found": {
+			status:  http.StatusUnprocessableEntity,
+			body:    `{"message": "Validation Failed", "errors": [{"resource": "Release", "code": "invalid", "field": "target_commitish"}]}`,
+			want:    commit.ErrTagNotFound,
+			message: "422 Unprocessable Entity: Validation Failed (Release target_commitish invalid)",
+		},
+		"string errors": {
+			status:  http.StatusUnprocessableEntity,
+			body:    `{"message": "Validation Failed", "errors": ["body is too long"]}`,
+			message: "422 Unprocessable Entity: Validation Failed (body is too long)",
+		},
+		"bad credentials": {
+			status:  http.StatusUnauthorized,
+			body:    `{"message": "Bad credentials", "documentation_url": "https://docs.github.com/rest"}`,
+			want:    commit.ErrUnauthorized,
+			message: "401 Unauthorized: Bad credentials",
+		},
+		"forbidden": {
+			status:  http.StatusForbidden,
+			body:    `{"message": "Resource not accessible by integration"}`,
+			want:    commit.ErrForbidden,
+			message: "403 Forbidden: Resource not accessible by integration",
+		},
+		"not json": {
+			status:  http.StatusBadGateway,
+			body:    "<html>bad gateway</html>\n",
+			message: "502 Bad Gateway: <html>bad gateway</html>",
+		},
+		"empty": {
+			status:  http.StatusInternalServerError,
+			message: "500 Internal Server Error",
+		},
+	}
+	for name, tc := range tcs {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tc.status)
+				fmt.Fprint(w, tc.body)
+			}))
+			t.Cleanup(srv.Close)
+
+			g := commit.Git{BaseURL: srv.URL}
+			err := g.Release(context.Background(), "token", "arsham", "gitrelease", "v0.1.0", "desc")
+			require.Error(t, err)
+			var ae *commit.APIError
+			require.True(t, errors.As(err, &ae), err)
+			assert.Equal(t, tc.status, ae.Code)
+			assert.Equal(t, tc.message, ae.Error())
+			for _, target := range all {
+				assert.Equal(t, target == tc.want, errors.Is(err, target), target)
+			}
+		})
+	}
+}
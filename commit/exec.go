@@ -0,0 +1,274 @@
+package commit
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// ExecRepository runs the git binary in the Dir. If Dir is empty, the current
+// folder is used.
+type ExecRepository struct {
+	Dir string
+}
+
+func (e ExecRepository) command(ctx context.Context, args ...string) *exec.Cmd {
+	// nolint:gosec // we need these variables.
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = e.Dir
+	// The messages are matched for finding the kind of the errors.
+	cmd.Env = append(os.Environ(), "LC_ALL=C")
+	return cmd
+}
+
+func (e ExecRepository) run(ctx context.Context, args ...string) (string, error) {
+	cmd := e.command(ctx, args...)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", &GitError{
+			Op:     "git " + args[0],
+			Output: string(out),
+			Err:    err,
+			Kind:   gitErrorKind(string(out)),
+		}
+	}
+	return string(out), nil
+}
+
+// LatestTag returns the last tag in the repository.
+func (e ExecRepository) LatestTag(ctx context.Context) (string, error) {
+	out, err := e.run(ctx, "describe", "--tags", "--abbrev=0")
+	return strings.Trim(out, "\n"), err
+}
+
+// PreviousTag returns the previous tag of the given tag.
+func (e ExecRepository) PreviousTag(ctx context.Context, tag string) (string, error) {
+	out, err := e.run(ctx, "describe", "--tags", "--abbrev=0", tag+"^")
+	var ge *GitError
+	if errors.As(err, &ge) && ge.Kind == ErrTagNotFound {
+		// The tag can be on the first commit, which has no parents.
+		if _, verr := e.run(ctx, "rev-parse", "--verify", "--quiet", tag+"^{commit}"); verr == nil {
+			ge.Kind = ErrNoTags
+		}
+	}
+	return strings.Trim(out, "\n"), err
+}
+
+// Tags returns all tags in the repository, sorted by their version from the
+// oldest to the newest.
+func (e ExecRepository) Tags(ctx context.Context) ([]string, error) {
+	out, err := e.run(ctx, "tag", "--list", "--sort=v:refname")
+	if err != nil {
+		return nil, err
+	}
+	return strings.Fields(out), nil
+}
+
+// logFormat prints the fields of the commits, each ending with a NUL.
+const logFormat = "%H%x00%P%x00%an%x00%ae%x00%aI%x00%cn%x00%ce%x00%cI%x00%(trailers:only,unfold)%x00%B%x00"
+
+// logFields is the number of the fields in the logFormat.
+const logFields = 10
+
+// Log calls fn with the commits between from and to. The output of git is
+// read as it is produced, and git is stopped if fn returns an error.
+func (e ExecRepository) Log(ctx context.Context, from, to string, opts LogOptions, fn func(Commit) error) error {
+	revRange := to
+	if from != "" {
+		revRange = fmt.Sprintf("%s..%s", from, to)
+	}
+	args := []string{"log", revRange, "--format=" + logFormat}
+	if opts.NoMerges {
+		args = append(args, "--no-merges")
+	}
+	if opts.FirstParent {
+		args = append(args, "--first-parent")
+	}
+	if opts.Files {
+		// The files of the merge commits are the ones their first parent
+		// doesn't have, which are the changes of the merged branch.
+		args = append(args, "--name-only", "--diff-merges=first-parent")
+	}
+
+	logCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	cmd := e.command(logCtx, args...)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return errors.Wrap(err, "reading git log")
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Start(); err != nil {
+		return &GitError{Op: "git log", Err: err}
+	}
+	perr := parseLog(bufio.NewReader(stdout), fn)
+	if perr != nil {
+		// There is no need for the rest of the log.
+		cancel()
+		// nolint:errcheck // it's killed.
+		cmd.Wait()
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		return perr
+	}
+	if err := cmd.Wait(); err != nil {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		return &GitError{
+			Op:     "git log",
+			Output: stderr.String(),
+			Err:    err,
+			Kind:   gitErrorKind(stderr.String()),
+		}
+	}
+	return nil
+}
+
+// parseLog reads the commits printed with the logFormat, and calls fn with
+// each one. The files of the commits printed with --name-only come after
+// their last field, therefore a commit is complete when the hash of the next
+// one, or the end of the output, is read.
+func parseLog(r *bufio.Reader, fn func(Commit) error) error {
+	var (
+		fields = make([]string, 0, logFields)
+		prev   *Commit
+	)
+	for {
+		field, err := r.ReadString(0)
+		if errors.Is(err, io.EOF) {
+			if len(fields) > 0 {
+				return fmt.Errorf("unexpected end of git log after %d fields", len(fields))
+			}
+			if prev == nil {
+				return nil
+			}
+			prev.Files = logFiles(field)
+			return fn(*prev)
+		}
+		if err != nil {
+			return errors.Wrap(err, "reading git log")
+		}
+		field = strings.TrimSuffix(field, "\x00")
+
+		if len(fields) == 0 {
+			// The hash is on the last line, after the files of the previous
+			// commit.
+			i := strings.LastIndexByte(field, '\n')
+			if prev != nil {
+				prev.Files = logFiles(field[:i+1])
+				if err := fn(*prev); err != nil {
+					return err
+				}
+				prev = nil
+			}
+			field = field[i+1:]
+		}
+		fields = append(fields, field)
+		if len(fields) < logFields {
+			continue
+		}
+		c, err := newCommit(fields)
+		if err != nil {
+			return err
+		}
+		prev = &c
+		fields = fields[:0]
+	}
+}
+
+// newCommit returns the commit from the fields of the logFormat.
+func newCommit(fields []string) (Commit, error) {
+	authorDate, err := time.Parse(time.RFC3339, fields[4])
+	if err != nil {
+		return Commit{}, errors.Wrapf(err, "parsing author date of %s", fields[0])
+	}
+	commitDate, err := time.Parse(time.RFC3339, fields[7])
+	if err != nil {
+		return Commit{}, errors.Wrapf(err, "parsing commit date of %s", fields[0])
+	}
+	return Commit{
+		Hash:      fields[0],
+		Parents:   strings.Fields(fields[1]),
+		Author:    Signature{Name: fields[2], Email: fields[3], When: authorDate},
+		Committer: Signature{Name: fields[5], Email: fields[6], When: commitDate},
+		Trailers:  parseTrailerLines(fields[8]),
+		Message:   fields[9],
+	}, nil
+}
+
+// logFiles returns the file names printed with --name-only.
+func logFiles(s string) []string {
+	var files []string
+	for _, file := range strings.Split(s, "\n") {
+		if file = strings.TrimSpace(file); file != "" {
+			files = append(files, file)
+		}
+	}
+	return files
+}
+
+// TagAnnotation returns the annotation of the tag, or nil if the tag is not
+// annotated.
+func (e ExecRepository) TagAnnotation(ctx context.Context, tag string) (*Annotation, error) {
+	out, err := e.run(ctx, "for-each-ref", "refs/tags/"+tag,
+		"--format=%(objecttype)%00%(taggername) %(taggeremail)%00%(taggerdate:unix)%00%(contents)")
+	if err != nil {
+		return nil, err
+	}
+	if out == "" {
+		return nil, fmt.Errorf("tag %s not found", tag)
+	}
+	parts := strings.SplitN(out, "\x00", 4)
+	if len(parts) != 4 {
+		return nil, fmt.Errorf("unexpected output: %q", out)
+	}
+	if parts[0] != "tag" {
+		return nil, nil
+	}
+	secs, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil {
+		return nil, errors.Wrapf(err, "parsing date of %s", tag)
+	}
+	return &Annotation{
+		Tagger:  parts[1],
+		Date:    time.Unix(secs, 0),
+		Message: strings.TrimSpace(parts[3]),
+	}, nil
+}
+
+// RemoteURL returns the address of the remote.
+func (e ExecRepository) RemoteURL(ctx context.Context, remote string) (string, error) {
+	out, err := e.run(ctx, "config", "--get", fmt.Sprintf("remote.%s.url", remote))
+	var ee *exec.ExitError
+	if errors.As(err, &ee) && ee.ExitCode() == 1 {
+		// git config exits with 1 when the key is not set, which is also the
+		// case outside of a repository.
+		if _, err := e.run(ctx, "rev-parse", "--git-dir"); err != nil {
+			return "", err
+		}
+		return "", &GitError{
+			Op:   "git config",
+			Err:  fmt.Errorf("remote %s has no url", remote),
+			Kind: ErrUnknownRemote,
+		}
+	}
+	return strings.Trim(out, "\n"), err
+}
+
+// GitPath returns the absolute path of the name inside the git directory.
+func (e ExecRepository) GitPath(ctx context.Context, name string) (string, error) {
+	out, err := e.run(ctx, "rev-parse", "--path-format=absolute", "--git-path", name)
+	return strings.Trim(out, "\n"), err
+}
@@ -0,0 +1,154 @@
+package commit
+
+import (
+	"path"
+	"regexp"
+	"strings"
+)
+
+// Rules match commits by their properties. A commit matches the rules if it
+// matches any of them.
+type Rules struct {
+	// Messages are matched against the whole commit message.
+	Messages []*regexp.Regexp
+	// Authors are matched against the author in the "Name <email>" form.
+	Authors []*regexp.Regexp
+	// Types are the commit types, e.g. "feat" or the "sparkles" gitmoji, or
+	// the section names they are listed in, e.g. "Feature". They are matched
+	// case-insensitively, and the types that are listed in the same section
+	// match each other.
+	Types []string
+	// Scopes are matched case-insensitively against each scope of the commit.
+	Scopes []string
+	// Paths are glob patterns matched against the files the commit touches.
+	// A pattern ending with a slash matches all files in the directory. The
+	// rule matches if any of the files match.
+	Paths []string
+}
+
+// Empty returns true if there are no rules.
+func (r Rules) Empty() bool {
+	return len(r.Messages) == 0 && len(r.Authors) == 0 && len(r.Types) == 0 &&
+		len(r.Scopes) == 0 && len(r.Paths) == 0
+}
+
+// Match returns true if the commit with the message, author and the touched
+// files matches any of the rules. The message is parsed with the
+// ConventionalCommits.
+func (r Rules) Match(msg, author string, files []string) bool {
+	return r.match(nil, msg, author, files)
+}
+
+// match is like Match, but parses the message with the convention. The
+// ConventionalCommits is used if it is nil.
+func (r Rules) match(c Convention, msg, author string, files []string) bool {
+	for _, re := range r.Messages {
+		if re.MatchString(msg) {
+			return true
+		}
+	}
+	for _, re := range r.Authors {
+		if re.MatchString(author) {
+			return true
+		}
+	}
+	if len(r.Types) > 0 || len(r.Scopes) > 0 {
+		if r.matchTitle(c, msg) {
+			return true
+		}
+	}
+	for _, file := range files {
+		if matchPath(r.Paths, file) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r Rules) matchTitle(c Convention, msg string) bool {
+	title := strings.SplitN(strings.TrimSpace(msg), "\n", 2)[0]
+	var (
+		group   Group
+		rawType string
+	)
+	switch c := c.(type) {
+	case nil, ConventionalCommits:
+		matches := descRe.FindStringSubmatch(title)
+		if matches == nil {
+			return containsFold(r.Types, "Misc")
+		}
+		group = GroupFromCommit(title)
+		rawType = strings.TrimSuffix(matches[1], "!")
+	default:
+		group = c.Group(title)
+	}
+	for _, t := range r.Types {
+		// The aliases of the types are listed in the same section.
+		verb := typeVerb(t)
+		if strings.EqualFold(t, rawType) || strings.EqualFold(t, group.Verb) || (verb != "Misc" && verb == group.Verb) {
+			return true
+		}
+	}
+	if group.Subject == "" {
+		return false
+	}
+	for _, scope := range strings.Split(group.Subject, ",") {
+		if containsFold(r.Scopes, scope) {
+			return true
+		}
+	}
+	return false
+}
+
+// typeVerb returns the section the commits of the type are listed in. The type
+// can be a Conventional Commits type, or a gitmoji with or without the colons.
+func typeVerb(t string) string {
+	if verb, ok := gitmojis[strings.Trim(strings.ToLower(t), ":")]; ok {
+		return verb
+	}
+	if code, ok := emojiShortcodes[strings.TrimSuffix(t, "\ufe0f")]; ok {
+		return gitmojis[code]
+	}
+	return GroupFromCommit(t + ":").Verb
+}
+
+func matchPath(patterns []string, file string) bool {
+	for _, pattern := range patterns {
+		if strings.HasSuffix(pattern, "/") && strings.HasPrefix(file, pattern) {
+			return true
+		}
+		if ok, err := path.Match(pattern, file); err == nil && ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Filter decides which commits are listed in the release notes. If there are
+// any Include rules, only the commits matching them are listed. The commits
+// matching the Exclude rules are never listed.
+type Filter struct {
+	Include Rules
+	Exclude Rules
+	// Convention is used for parsing the messages for the Types and Scopes
+	// rules. The default is ConventionalCommits.
+	Convention Convention
+}
+
+// Empty returns true if the filter keeps all commits.
+func (f Filter) Empty() bool {
+	return f.Include.Empty() && f.Exclude.Empty()
+}
+
+// Keep returns true if the commit should be listed.
+func (f Filter) Keep(msg, author string, files []string) bool {
+	if !f.Include.Empty() && !f.Include.match(f.Convention, msg, author, files) {
+		return false
+	}
+	return !f.Exclude.match(f.Convention, msg, author, files)
+}
+
+// needsFiles returns true if the filter has rules for the touched files.
+func (f Filter) needsFiles() bool {
+	return len(f.Include.Paths) > 0 || len(f.Exclude.Paths) > 0
+}
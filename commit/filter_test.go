@@ -0,0 +1,117 @@
+package commit_test
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/arsham/gitrelease/commit"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFilterKeep(t *testing.T) {
+	t.Parallel()
+	bot := "dependabot[bot] <49699333+dependabot[bot]@users.noreply.github.com>"
+	human := "arsham <arsham@github.com>"
+	type commitInfo struct {
+		msg    string
+		author string
+		files  []string
+	}
+	tcs := map[string]struct {
+		filter commit.Filter
+		keep   []commitInfo
+		drop   []commitInfo
+	}{
+		"empty": {
+			keep: []commitInfo{{"fix: something", human, nil}},
+		},
+		"exclude message": {
+			filter: commit.Filter{Exclude: commit.Rules{
+				Messages: []*regexp.Regexp{regexp.MustCompile(`^Bump `)},
+			}},
+			keep: []commitInfo{{"fix: Bump something", human, nil}},
+			drop: []commitInfo{{"Bump x from 1 to 2", human, nil}},
+		},
+		"exclude author": {
+			filter: commit.Filter{Exclude: commit.Rules{
+				Authors: []*regexp.Regexp{regexp.MustCompile(`\[bot\]`)},
+			}},
+			keep: []commitInfo{{"fix: something", human, nil}},
+			drop: []commitInfo{{"fix: something", bot, nil}},
+		},
+		"exclude type": {
+			filter: commit.Filter{Exclude: commit.Rules{Types: []string{"chore", "Docs", "build"}}},
+			keep:   []commitInfo{{"fix: something", human, nil}},
+			drop: []commitInfo{
+				{"chore: something", human, nil},
+				{"docs(readme): something", human, nil},
+				{"build(deps): bump x", human, nil},
+			},
+		},
+		"exclude misc": {
+			filter: commit.Filter{Exclude: commit.Rules{Types: []string{"misc"}}},
+			keep:   []commitInfo{{"fix: something", human, nil}},
+			drop:   []commitInfo{{"something", human, nil}, {"123 something", human, nil}},
+		},
+		"exclude scope": {
+			filter: commit.Filter{Exclude: commit.Rules{Scopes: []string{"deps"}}},
+			keep:   []commitInfo{{"fix: something", human, nil}, {"fix(api): something", human, nil}},
+			drop:   []commitInfo{{"build(deps): bump x", human, nil}, {"fix(api,Deps): something", human, nil}},
+		},
+		"exclude paths": {
+			filter: commit.Filter{Exclude: commit.Rules{Paths: []string{"docs/", "*.md"}}},
+			keep:   []commitInfo{{"fix: something", human, []string{"main.go"}}, {"fix: something", human, nil}},
+			drop: []commitInfo{
+				{"fix: something", human, []string{"docs/usage/index.txt"}},
+				{"fix: something", human, []string{"main.go", "README.md"}},
+			},
+		},
+		"include": {
+			filter: commit.Filter{Include: commit.Rules{Types: []string{"feat", "fix"}}},
+			keep:   []commitInfo{{"fix: something", human, nil}, {"feature: something", human, nil}},
+			drop:   []commitInfo{{"chore: something", human, nil}},
+		},
+		"gitmoji types": {
+			filter: commit.Filter{
+				Exclude:    commit.Rules{Types: []string{"chore", ":memo:", "rewind"}},
+				Convention: commit.Gitmoji{},
+			},
+			keep: []commitInfo{{":sparkles: add x", human, nil}, {"chore: something", human, nil}},
+			drop: []commitInfo{
+				{":wrench: something", human, nil},
+				{"🔨 something", human, nil},
+				{"📝 (readme) update", human, nil},
+				{":rewind: revert x", human, nil},
+			},
+		},
+		"gitmoji scopes": {
+			filter: commit.Filter{
+				Include:    commit.Rules{Scopes: []string{"plat-12"}},
+				Convention: commit.Gitmoji{},
+			},
+			keep: []commitInfo{{":bug: (PLAT-12): fix x", human, nil}},
+			drop: []commitInfo{{":bug: (api): fix x", human, nil}, {"fix(PLAT-12): fix x", human, nil}},
+		},
+		"include and exclude": {
+			filter: commit.Filter{
+				Include: commit.Rules{Types: []string{"fix"}},
+				Exclude: commit.Rules{Authors: []*regexp.Regexp{regexp.MustCompile(`\[bot\]`)}},
+			},
+			keep: []commitInfo{{"fix: something", human, nil}},
+			drop: []commitInfo{{"fix: something", bot, nil}, {"chore: something", human, nil}},
+		},
+	}
+
+	for name, tc := range tcs {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			for _, c := range tc.keep {
+				assert.True(t, tc.filter.Keep(c.msg, c.author, c.files), c)
+			}
+			for _, c := range tc.drop {
+				assert.False(t, tc.filter.Keep(c.msg, c.author, c.files), c)
+			}
+		})
+	}
+}
@@ -3,82 +3,103 @@
 package commit
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
 	"fmt"
 	"net/http"
-	"os/exec"
 	"regexp"
-	"strings"
-
-	"github.com/github-release/github-release/github"
-	"github.com/pkg/errors"
 )
 
-const baseURL = "https://api.github.com"
-
-// Git executes git processes targeted at a directory. If the Dir property is
-// empty, all calls will be on the current folder.
+// Git reads the repository in a directory. If the Dir property is empty, all
+// calls will be on the current folder. The Backend decides how the repository
+// is read, and defaults to BackendExec. If Repo is set, it is used instead of
+// the Backend and the Dir. The BaseURL is the address of the GitHub API, and
+// defaults to https://api.github.com if empty. The Client makes the API calls,
+// and can use a Transport for retrying them. The MergeStrategy decides how
+// Commits returns the merge commits, and defaults to MergeKeep. The Filter
+// decides which commits are returned by Commits.
 type Git struct {
-	Dir    string
-	Remote string
+	Dir           string
+	Remote        string
+	BaseURL       string
+	Client        *http.Client
+	Backend       Backend
+	Repo          Repository
+	MergeStrategy MergeStrategy
+	Filter        Filter
 }
 
-// LatestTag returns the last tag in the repository.
-func (g Git) LatestTag(ctx context.Context) (string, error) {
-	args := []string{
-		"describe",
-		"--tags",
-		"--abbrev=0",
-	}
-	cmd := exec.CommandContext(ctx, "git", args...)
-	cmd.Dir = g.Dir
-	out, err := cmd.CombinedOutput()
-	if err != nil {
-		return "", errors.Wrap(err, string(out))
+func (g Git) repo() Repository {
+	if g.Repo != nil {
+		return g.Repo
 	}
+	return g.Backend.repository(g.Dir)
+}
 
-	return strings.Trim(string(out), "\n"), nil
+// LatestTag returns the last tag in the repository.
+func (g Git) LatestTag(ctx context.Context) (string, error) {
+	return g.repo().LatestTag(ctx)
 }
 
 // PreviousTag returns the previous tag of the given tag.
 func (g Git) PreviousTag(ctx context.Context, tag string) (string, error) {
-	args := []string{
-		"describe",
-		"--tags",
-		"--abbrev=0",
-		tag + "^",
-	}
-	// nolint:gosec // we don't have any other way to get the previous tag.
-	cmd := exec.CommandContext(ctx, "git", args...)
-	cmd.Dir = g.Dir
-	out, err := cmd.CombinedOutput()
-	if err != nil {
-		return "", errors.Wrap(err, string(out))
-	}
+	return g.repo().PreviousTag(ctx, tag)
+}
 
-	return strings.Trim(string(out), "\n"), nil
+// Tags returns all tags in the repository, sorted by their version from the
+// oldest to the newest.
+func (g Git) Tags(ctx context.Context) ([]string, error) {
+	return g.repo().Tags(ctx)
 }
 
-// Commits returns the contents of all commits between two tags.
-func (g Git) Commits(ctx context.Context, tag1, tag2 string) ([]string, error) {
-	separator := "00000000000000000000000000000000000"
-	args := []string{
-		"log",
-		"--oneline",
-		fmt.Sprintf("%s..%s", tag1, tag2),
-		fmt.Sprintf("--pretty=%s%%B", separator),
-	}
-	// nolint:gosec // we need these variables.
-	cmd := exec.CommandContext(ctx, "git", args...)
-	cmd.Dir = g.Dir
-	out, err := cmd.CombinedOutput()
+// Commits returns all commits between two tags, from the newest to the
+// oldest. If tag1 is empty, all commits reachable from tag2 are returned. The
+// commits the Filter doesn't keep are left out. All commits are held in memory,
+// use EachCommit for going through them one by one.
+func (g Git) Commits(ctx context.Context, tag1, tag2 string) ([]Commit, error) {
+	var commits []Commit
+	err := g.EachCommit(ctx, tag1, tag2, func(c Commit) error {
+		commits = append(commits, c)
+		return nil
+	})
 	if err != nil {
-		return nil, errors.Wrap(err, string(out))
+		return nil, err
 	}
-	logs := strings.Split(string(out), separator)
-	return logs, nil
+	return commits, nil
+}
+
+// EachCommit calls fn with the commits between two tags, from the newest to
+// the oldest. If tag1 is empty, all commits reachable from tag2 are walked.
+// The commits the Filter doesn't keep are skipped, and the messages of the
+// merge commits are changed as the MergeStrategy decides. It stops and returns
+// the error if fn returns one.
+//
+// The BackendExec passes each commit to fn as git prints it, therefore only
+// one commit is held in memory at a time. The BackendGo has to sort the commits
+// by their dates first, and keeps the hashes of the whole range for that.
+func (g Git) EachCommit(ctx context.Context, tag1, tag2 string, fn func(Commit) error) error {
+	opts := g.MergeStrategy.logOptions()
+	opts.Files = g.Filter.needsFiles()
+	return g.repo().Log(ctx, tag1, tag2, opts, func(c Commit) error {
+		if g.MergeStrategy == MergePR {
+			c.Message = prMessage(c.Message)
+		}
+		if !g.Filter.Keep(c.Message, c.Author.String(), c.Files) {
+			return nil
+		}
+		return fn(c)
+	})
+}
+
+// HooksDir returns the absolute path of the directory git runs the hooks from.
+// It respects the core.hooksPath setting.
+func (g Git) HooksDir(ctx context.Context) (string, error) {
+	return g.GitPath(ctx, "hooks")
+}
+
+// GitPath returns the absolute path of the name inside the git directory, e.g.
+// .git/name.
+func (g Git) GitPath(ctx context.Context, name string) (string, error) {
+	return g.repo().GitPath(ctx, name)
 }
 
 var infoRe = regexp.MustCompile(`github\.com[:/](?P<user>[^/]+)/(?P<repo>.+?)(?:.git)?\n?$`)
@@ -88,71 +109,17 @@ func (g Git) RepoInfo(ctx context.Context) (user, repo string, err error) {
 	if g.Remote == "" {
 		g.Remote = "origin"
 	}
-	args := []string{
-		"config",
-		"--get",
-		fmt.Sprintf("remote.%s.url", g.Remote),
-	}
-	// nolint:gosec // it is required.
-	cmd := exec.CommandContext(ctx, "git", args...)
-	cmd.Dir = g.Dir
-	out, err := cmd.CombinedOutput()
+	addr, err := g.repo().RemoteURL(ctx, g.Remote)
 	if err != nil {
-		return "", "", errors.Wrap(err, string(out))
+		return "", "", err
 	}
 
-	info := infoRe.FindStringSubmatch(string(out))
+	info := infoRe.FindStringSubmatch(addr)
 	if len(info) != 3 {
-		return "", "", fmt.Errorf("could not parse repository info: %s", string(out))
+		return "", "", fmt.Errorf("could not parse repository info: %s", addr)
 	}
 	user = info[1]
 	repo = info[2]
 
 	return user, repo, nil
 }
-
-type releaseCreate struct {
-	TagName         string `json:"tag_name"`
-	TargetCommitish string `json:"target_commitish,omitempty"`
-	Name            string `json:"name"`
-	Body            string `json:"body"`
-	Draft           bool   `json:"draft"`
-	Prerelease      bool   `json:"prerelease"`
-}
-
-// Release publishes the release for the user on the repo.
-func (g Git) Release(ctx context.Context, token, user, repo, tag, desc string) error {
-	params := releaseCreate{
-		TagName: tag,
-		Body:    desc,
-	}
-
-	payload, err := json.Marshal(params)
-	if err != nil {
-		return errors.Wrap(err, "marshalling values")
-	}
-
-	client := github.NewClient(repo, token, nil)
-	client.SetBaseURL(baseURL)
-	reader := bytes.NewReader(payload)
-	req, err := client.NewRequest("POST", fmt.Sprintf("/repos/%s/%s/releases", user, repo), reader)
-	if err != nil {
-		return errors.Wrapf(err, "creating request to the API: %q", string(payload))
-	}
-	req = req.WithContext(ctx)
-
-	resp, err := client.Do(req)
-	if err != nil {
-		return errors.Wrapf(err, "submitting to the API: %q", string(payload))
-	}
-	// nolint:errcheck // it's ok.
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusCreated {
-		if resp.StatusCode == 422 {
-			return errors.New("release already exists")
-		}
-		return fmt.Errorf("error publishing release with code: %q", resp.Status)
-	}
-	return nil
-}
@@ -2,9 +2,14 @@ package commit_test
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os/exec"
+	"path"
+	"regexp"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/arsham/gitrelease/commit"
 	"github.com/blokur/testament"
@@ -15,205 +20,736 @@ import (
 
 func TestGit(t *testing.T) {
 	t.Parallel()
-	t.Run("LatestTag", testGitLatestTag)
-	t.Run("PreviousTag", testGitPreviousTag)
-	t.Run("Commits", testGitCommits)
-	t.Run("RepoInfo", testGitRepoInfo)
+	for _, backend := range []commit.Backend{commit.BackendExec, commit.BackendGo} {
+		backend := backend
+		t.Run(string(backend), func(t *testing.T) {
+			t.Parallel()
+			t.Run("LatestTag", testGitLatestTag(backend))
+			t.Run("PreviousTag", testGitPreviousTag(backend))
+			t.Run("Tags", testGitTags(backend))
+			t.Run("Commits", testGitCommits(backend))
+			t.Run("CommitsFromStart", testGitCommitsFromStart(backend))
+			t.Run("CommitsMergeStrategy", testGitCommitsMergeStrategy(backend))
+			t.Run("CommitsFilter", testGitCommitsFilter(backend))
+			t.Run("CommitsFilterMerges", testGitCommitsFilterMerges(backend))
+			t.Run("RepoInfo", testGitRepoInfo(backend))
+			t.Run("HooksDir", testGitHooksDir(backend))
+			t.Run("AnnotatedTags", testGitAnnotatedTags(backend))
+			t.Run("TagAnnotation", testGitTagAnnotation(backend))
+			t.Run("Errors", testGitErrors(backend))
+			t.Run("EachCommit", testGitEachCommit(backend))
+		})
+	}
 }
 
-func testGitLatestTag(t *testing.T) {
-	t.Parallel()
-	ctx := context.Background()
-	dir := createGitRepo(t)
+func testGitLatestTag(backend commit.Backend) func(t *testing.T) {
+	return func(t *testing.T) {
+		t.Parallel()
+		ctx := context.Background()
+		dir := createGitRepo(t)
 
-	g := commit.Git{
-		Dir: dir,
-	}
+		g := commit.Git{
+			Dir:     dir,
+			Backend: backend,
+		}
 
-	_, err := g.LatestTag(ctx)
-	assert.Error(t, err)
+		_, err := g.LatestTag(ctx)
+		assert.Error(t, err)
 
-	createFile(t, dir, "file.txt", testament.RandomString(20))
-	commitChanges(t, dir, testament.RandomString(20))
-	createGitTag(t, dir, "v0.0.1")
+		createFile(t, dir, "file.txt", testament.RandomString(20))
+		commitChanges(t, dir, testament.RandomString(20))
+		createGitTag(t, dir, "v0.0.1")
 
-	got, err := g.LatestTag(ctx)
-	require.NoError(t, err)
-	assert.Equal(t, "v0.0.1", got)
+		got, err := g.LatestTag(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, "v0.0.1", got)
 
-	createFile(t, dir, "file2.txt", testament.RandomString(20))
-	commitChanges(t, dir, testament.RandomString(20))
-	createGitTag(t, dir, "v0.0.2")
+		createFile(t, dir, "file2.txt", testament.RandomString(20))
+		commitChanges(t, dir, testament.RandomString(20))
+		createGitTag(t, dir, "v0.0.2")
 
-	createFile(t, dir, "file3.txt", testament.RandomString(20))
-	commitChanges(t, dir, testament.RandomString(20))
-	got, err = g.LatestTag(ctx)
-	require.NoError(t, err)
-	assert.Equal(t, "v0.0.2", got)
+		createFile(t, dir, "file3.txt", testament.RandomString(20))
+		commitChanges(t, dir, testament.RandomString(20))
+		got, err = g.LatestTag(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, "v0.0.2", got)
+	}
 }
 
-func testGitPreviousTag(t *testing.T) {
-	t.Parallel()
-	ctx := context.Background()
-	dir := createGitRepo(t)
+func testGitPreviousTag(backend commit.Backend) func(t *testing.T) {
+	return func(t *testing.T) {
+		t.Parallel()
+		ctx := context.Background()
+		dir := createGitRepo(t)
+
+		g := commit.Git{
+			Dir:     dir,
+			Backend: backend,
+		}
+
+		_, err := g.PreviousTag(ctx, "v0.0.10")
+		assert.Error(t, err)
+
+		createFile(t, dir, "file.txt", testament.RandomString(20))
+		commitChanges(t, dir, testament.RandomString(20))
+		createGitTag(t, dir, "v0.0.1")
+
+		createFile(t, dir, "file2.txt", testament.RandomString(20))
+		commitChanges(t, dir, testament.RandomString(20))
+		createGitTag(t, dir, "v0.0.2")
 
-	g := commit.Git{
-		Dir: dir,
+		got, err := g.PreviousTag(ctx, "v0.0.2")
+		require.NoError(t, err)
+		assert.Equal(t, "v0.0.1", got)
+
+		createFile(t, dir, "file3.txt", testament.RandomString(20))
+		commitChanges(t, dir, testament.RandomString(20))
+		got, err = g.PreviousTag(ctx, "@")
+		require.NoError(t, err)
+		assert.Equal(t, "v0.0.2", got)
 	}
+}
 
-	_, err := g.PreviousTag(ctx, "v0.0.10")
-	assert.Error(t, err)
+func testGitTags(backend commit.Backend) func(t *testing.T) {
+	return func(t *testing.T) {
+		t.Parallel()
+		ctx := context.Background()
+		dir := createGitRepo(t)
 
-	createFile(t, dir, "file.txt", testament.RandomString(20))
-	commitChanges(t, dir, testament.RandomString(20))
-	createGitTag(t, dir, "v0.0.1")
+		g := commit.Git{
+			Dir:     dir,
+			Backend: backend,
+		}
 
-	createFile(t, dir, "file2.txt", testament.RandomString(20))
-	commitChanges(t, dir, testament.RandomString(20))
-	createGitTag(t, dir, "v0.0.2")
+		got, err := g.Tags(ctx)
+		require.NoError(t, err)
+		assert.Empty(t, got)
 
-	got, err := g.PreviousTag(ctx, "v0.0.2")
-	require.NoError(t, err)
-	assert.Equal(t, "v0.0.1", got)
+		want := []string{"v0.0.1", "v0.0.2", "v0.0.10", "v0.1.0"}
+		for _, tag := range []string{"v0.0.10", "v0.0.1", "v0.1.0", "v0.0.2"} {
+			createFile(t, dir, "file.txt", testament.RandomString(20))
+			commitChanges(t, dir, testament.RandomString(20))
+			createGitTag(t, dir, tag)
+		}
 
-	createFile(t, dir, "file3.txt", testament.RandomString(20))
-	commitChanges(t, dir, testament.RandomString(20))
-	got, err = g.PreviousTag(ctx, "@")
-	require.NoError(t, err)
-	assert.Equal(t, "v0.0.2", got)
+		got, err = g.Tags(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, want, got)
+	}
 }
 
-func testGitCommits(t *testing.T) {
-	t.Parallel()
-	ctx := context.Background()
-	dir := createGitRepo(t)
+func testGitCommits(backend commit.Backend) func(t *testing.T) {
+	return func(t *testing.T) {
+		t.Parallel()
+		ctx := context.Background()
+		dir := createGitRepo(t)
+
+		g := commit.Git{
+			Dir:     dir,
+			Backend: backend,
+		}
+
+		filename := "file.txt"
+
+		createFile(t, dir, filename, testament.RandomString(20))
+		commitChanges(t, dir, "msg1")
+		createGitTag(t, dir, "v0.0.1")
+
+		msgs := []string{"msg1", "msg2", "msg3"}
+		for _, msg := range msgs {
+			appendToFile(t, dir, filename, testament.RandomString(20))
+			commitChanges(t, dir, msg)
+		}
 
-	g := commit.Git{
-		Dir: dir,
+		createGitTag(t, dir, "v0.0.2")
+
+		got, err := g.Commits(ctx, "v0.0.1", "v0.0.2")
+		require.NoError(t, err)
+		if diff := cmp.Diff(msgs, messages(got), commitComparer...); diff != "" {
+			t.Errorf("(-want +got):\n%s", diff)
+		}
 	}
+}
 
-	filename := "file.txt"
+func testGitCommitsFromStart(backend commit.Backend) func(t *testing.T) {
+	return func(t *testing.T) {
+		t.Parallel()
+		ctx := context.Background()
+		dir := createGitRepo(t)
 
-	createFile(t, dir, filename, testament.RandomString(20))
-	commitChanges(t, dir, "msg1")
-	createGitTag(t, dir, "v0.0.1")
+		g := commit.Git{
+			Dir:     dir,
+			Backend: backend,
+		}
+
+		filename := "file.txt"
+		msgs := []string{"msg1", "msg2", "msg3"}
+		for _, msg := range msgs {
+			appendToFile(t, dir, filename, testament.RandomString(20))
+			commitChanges(t, dir, msg)
+		}
+		createGitTag(t, dir, "v0.0.1")
+
+		got, err := g.Commits(ctx, "", "v0.0.1")
+		require.NoError(t, err)
+		if diff := cmp.Diff(msgs, messages(got), commitComparer...); diff != "" {
+			t.Errorf("(-want +got):\n%s", diff)
+		}
+	}
+}
+
+func testGitCommitsMergeStrategy(backend commit.Backend) func(t *testing.T) {
+	return func(t *testing.T) {
+		t.Parallel()
+		dir := createGitRepo(t)
+		filename := "file.txt"
+
+		createFile(t, dir, filename, testament.RandomString(20))
+		commitChanges(t, dir, "msg1")
+		createGitTag(t, dir, "v0.0.1")
+		runGit(t, dir, "branch", "-M", "main")
+
+		runGit(t, dir, "checkout", "-b", "feature")
+		createFile(t, dir, "feature.txt", testament.RandomString(20))
+		commitChanges(t, dir, "wip 1")
+		appendToFile(t, dir, "feature.txt", testament.RandomString(20))
+		commitChanges(t, dir, "wip 2")
+		runGit(t, dir, "checkout", "main")
+		runGit(t, dir, "merge", "--no-ff", "--no-gpg-sign", "feature",
+			"-m", "Merge pull request #12 from arsham/feature", "-m", "feat(api): add x")
 
-	msgs := []string{"msg1", "msg2", "msg3"}
-	for _, msg := range msgs {
 		appendToFile(t, dir, filename, testament.RandomString(20))
-		commitChanges(t, dir, msg)
+		commitChanges(t, dir, "fix: thing (#13)\n\n* wip a\n\n* fix #45 thing\n\nClose #46")
+		appendToFile(t, dir, filename, testament.RandomString(20))
+		commitChanges(t, dir, "fix: other thing\n\n* fixes #47")
+		createGitTag(t, dir, "v0.0.2")
+
+		tcs := map[commit.MergeStrategy][]string{
+			commit.MergeKeep: {
+				"Merge pull request #12 from arsham/feature\n\nfeat(api): add x",
+				"wip 1",
+				"wip 2",
+				"fix: thing (#13)\n\n* wip a\n\n* fix #45 thing\n\nClose #46",
+				"fix: other thing\n\n* fixes #47",
+			},
+			commit.MergeSkip: {
+				"wip 1",
+				"wip 2",
+				"fix: thing (#13)\n\n* wip a\n\n* fix #45 thing\n\nClose #46",
+				"fix: other thing\n\n* fixes #47",
+			},
+			commit.MergePR: {
+				"feat(api): add x (#12)",
+				"fix: thing (#13)\n\n\n\nClose #46",
+				"fix: other thing\n\n* fixes #47",
+			},
+		}
+		for strategy, want := range tcs {
+			strategy, want := strategy, want
+			t.Run(string(strategy), func(t *testing.T) {
+				g := commit.Git{
+					Dir:           dir,
+					Backend:       backend,
+					MergeStrategy: strategy,
+				}
+				got, err := g.Commits(context.Background(), "v0.0.1", "v0.0.2")
+				require.NoError(t, err)
+				if diff := cmp.Diff(want, messages(got), commitComparer...); diff != "" {
+					t.Errorf("(-want +got):\n%s", diff)
+				}
+			})
+		}
 	}
+}
 
-	createGitTag(t, dir, "v0.0.2")
+func testGitCommitsFilter(backend commit.Backend) func(t *testing.T) {
+	return func(t *testing.T) {
+		t.Parallel()
+		dir := createGitRepo(t)
+
+		createFile(t, dir, "main.go", testament.RandomString(20))
+		commitChanges(t, dir, "msg1")
+		createGitTag(t, dir, "v0.0.1")
+
+		createFile(t, dir, "main.go", testament.RandomString(20))
+		commitChanges(t, dir, "fix: code")
+		createFile(t, dir, "README.md", testament.RandomString(20))
+		commitChanges(t, dir, "docs: readme")
+		createFile(t, dir, "go.mod", testament.RandomString(20))
+		runGit(t, dir, "add", "-A")
+		runGit(t, dir, "-c", "user.name=dependabot[bot]", "-c", "user.email=bot@github.com",
+			"commit", "-m", "build(deps): bump x", "--no-gpg-sign")
+		createGitTag(t, dir, "v0.0.2")
+
+		tcs := map[string]struct {
+			filter commit.Filter
+			want   []string
+		}{
+			"none": {
+				want: []string{"fix: code", "docs: readme", "build(deps): bump x"},
+			},
+			"author": {
+				filter: commit.Filter{Exclude: commit.Rules{
+					Authors: []*regexp.Regexp{regexp.MustCompile(`\[bot\] <`)},
+				}},
+				want: []string{"fix: code", "docs: readme"},
+			},
+			"paths": {
+				filter: commit.Filter{Exclude: commit.Rules{Paths: []string{"*.md"}}},
+				want:   []string{"fix: code", "build(deps): bump x"},
+			},
+			"include paths": {
+				filter: commit.Filter{Include: commit.Rules{Paths: []string{"*.go"}}},
+				want:   []string{"fix: code"},
+			},
+		}
+		for name, tc := range tcs {
+			tc := tc
+			t.Run(name, func(t *testing.T) {
+				g := commit.Git{
+					Dir:     dir,
+					Backend: backend,
+					Filter:  tc.filter,
+				}
+				got, err := g.Commits(context.Background(), "v0.0.1", "v0.0.2")
+				require.NoError(t, err)
+				if diff := cmp.Diff(tc.want, messages(got), commitComparer...); diff != "" {
+					t.Errorf("(-want +got):\n%s", diff)
+				}
+			})
+		}
+	}
+}
 
-	got, err := g.Commits(ctx, "v0.0.1", "v0.0.2")
-	require.NoError(t, err)
-	if diff := cmp.Diff(msgs, got, commitComparer...); diff != "" {
-		t.Errorf("(-want +got):\n%s", diff)
+func testGitCommitsFilterMerges(backend commit.Backend) func(t *testing.T) {
+	return func(t *testing.T) {
+		t.Parallel()
+		dir := createGitRepo(t)
+
+		createFile(t, dir, "README.md", testament.RandomString(20))
+		commitChanges(t, dir, "msg1")
+		createGitTag(t, dir, "v0.0.1")
+		runGit(t, dir, "branch", "-M", "main")
+
+		runGit(t, dir, "checkout", "-b", "feature")
+		createFile(t, dir, "main.go", testament.RandomString(20))
+		commitChanges(t, dir, "wip 1")
+		runGit(t, dir, "checkout", "main")
+		appendToFile(t, dir, "README.md", testament.RandomString(20))
+		commitChanges(t, dir, "docs: readme")
+		runGit(t, dir, "merge", "--no-ff", "--no-gpg-sign", "feature",
+			"-m", "Merge pull request #12 from arsham/feature", "-m", "feat(api): add x")
+		createGitTag(t, dir, "v0.0.2")
+
+		tcs := map[commit.MergeStrategy][]string{
+			commit.MergeKeep: {
+				"Merge pull request #12 from arsham/feature\n\nfeat(api): add x",
+				"wip 1",
+			},
+			commit.MergePR: {
+				"feat(api): add x (#12)",
+			},
+		}
+		for strategy, want := range tcs {
+			strategy, want := strategy, want
+			t.Run(string(strategy), func(t *testing.T) {
+				g := commit.Git{
+					Dir:           dir,
+					Backend:       backend,
+					MergeStrategy: strategy,
+					Filter:        commit.Filter{Include: commit.Rules{Paths: []string{"*.go"}}},
+				}
+				got, err := g.Commits(context.Background(), "v0.0.1", "v0.0.2")
+				require.NoError(t, err)
+				if diff := cmp.Diff(want, messages(got), commitComparer...); diff != "" {
+					t.Errorf("(-want +got):\n%s", diff)
+				}
+			})
+		}
 	}
 }
 
-func testGitRepoInfo(t *testing.T) {
-	t.Run("Repo", testGitRepoInfoRepo)
-	t.Run("Remote", testGitRepoInfoRemote)
+func testGitRepoInfo(backend commit.Backend) func(t *testing.T) {
+	return func(t *testing.T) {
+		t.Run("Repo", testGitRepoInfoRepo(backend))
+		t.Run("Remote", testGitRepoInfoRemote(backend))
+	}
 }
 
-func testGitRepoInfoRepo(t *testing.T) {
-	t.Parallel()
+func testGitRepoInfoRepo(backend commit.Backend) func(t *testing.T) {
+	return func(t *testing.T) {
+		t.Parallel()
+
+		wantUser := "arsham666"
+		wantRepo := "gitrelease777"
+		addrs := map[string]struct {
+			addr     string
+			wantUser string
+			wantRepo string
+		}{
+			"git protocol":          {"git@github.com:%s/%s", wantUser, wantRepo},
+			"git protocol dot":      {"git@github.com:%s/%s", wantUser, wantRepo + ".nvim"},
+			"git protocol tail":     {"git@github.com:%s/%s.git", wantUser, wantRepo},
+			"git protocol tail dot": {"git@github.com:%s/%s.git", wantUser, wantRepo + ".nvim"},
+			"no protocol":           {"github.com/%s/%s", wantUser, wantRepo},
+			"no protocol dot":       {"github.com/%s/%s", wantUser, wantRepo + ".nvim"},
+			"no protocol tail":      {"github.com/%s/%s.git", wantUser, wantRepo},
+			"no protocol tail dot":  {"github.com/%s/%s.git", wantUser, wantRepo + ".nvim"},
+			"protocol":              {"https://github.com/%s/%s", wantUser, wantRepo},
+			"protocol dot":          {"https://github.com/%s/%s", wantUser, wantRepo + ".nvim"},
+			"protocol tail":         {"https://github.com/%s/%s.git", wantUser, wantRepo},
+			"protocol tail dot":     {"https://github.com/%s/%s.git", wantUser, wantRepo + ".nvim"},
+		}
 
-	wantUser := "arsham666"
-	wantRepo := "gitrelease777"
-	addrs := map[string]struct {
-		addr     string
-		wantUser string
-		wantRepo string
-	}{
-		"git protocol":          {"git@github.com:%s/%s", wantUser, wantRepo},
-		"git protocol dot":      {"git@github.com:%s/%s", wantUser, wantRepo + ".nvim"},
-		"git protocol tail":     {"git@github.com:%s/%s.git", wantUser, wantRepo},
-		"git protocol tail dot": {"git@github.com:%s/%s.git", wantUser, wantRepo + ".nvim"},
-		"no protocol":           {"github.com/%s/%s", wantUser, wantRepo},
-		"no protocol dot":       {"github.com/%s/%s", wantUser, wantRepo + ".nvim"},
-		"no protocol tail":      {"github.com/%s/%s.git", wantUser, wantRepo},
-		"no protocol tail dot":  {"github.com/%s/%s.git", wantUser, wantRepo + ".nvim"},
-		"protocol":              {"https://github.com/%s/%s", wantUser, wantRepo},
-		"protocol dot":          {"https://github.com/%s/%s", wantUser, wantRepo + ".nvim"},
-		"protocol tail":         {"https://github.com/%s/%s.git", wantUser, wantRepo},
-		"protocol tail dot":     {"https://github.com/%s/%s.git", wantUser, wantRepo + ".nvim"},
-	}
-
-	for name, tc := range addrs {
-		tc := tc
-		t.Run(name, func(t *testing.T) {
-			dir := createGitRepo(t)
-			addr := fmt.Sprintf(tc.addr, tc.wantUser, tc.wantRepo)
-			g := commit.Git{
-				Dir: dir,
-			}
+		for name, tc := range addrs {
+			tc := tc
+			t.Run(name, func(t *testing.T) {
+				dir := createGitRepo(t)
+				addr := fmt.Sprintf(tc.addr, tc.wantUser, tc.wantRepo)
+				g := commit.Git{
+					Dir:     dir,
+					Backend: backend,
+				}
+				args := []string{
+					"remote",
+					"add",
+					"origin",
+					addr,
+				}
+				cmd := exec.CommandContext(context.Background(), "git", args...)
+				cmd.Dir = dir
+				out, err := cmd.CombinedOutput()
+				require.NoError(t, err, string(out))
+
+				user, repo, err := g.RepoInfo(context.Background())
+				require.NoError(t, err, addr)
+				assert.Equal(t, tc.wantUser, user)
+				assert.Equal(t, tc.wantRepo, repo)
+			})
+		}
+	}
+}
+
+func testGitRepoInfoRemote(backend commit.Backend) func(t *testing.T) {
+	return func(t *testing.T) {
+		t.Parallel()
+		dir := createGitRepo(t)
+		g := commit.Git{
+			Dir:     dir,
+			Backend: backend,
+		}
+
+		setup := []struct {
+			addr   string
+			remote string
+		}{{
+			addr:   "git@github.com:arsham/shark.git",
+			remote: "origin",
+		}, {
+			addr:   "git@github.com:arsham/arshlib.nvim.git",
+			remote: "other",
+		}}
+
+		for _, s := range setup {
 			args := []string{
 				"remote",
 				"add",
-				"origin",
-				addr,
+				s.remote,
+				s.addr,
 			}
 			cmd := exec.CommandContext(context.Background(), "git", args...)
 			cmd.Dir = dir
 			out, err := cmd.CombinedOutput()
 			require.NoError(t, err, string(out))
+		}
 
-			user, repo, err := g.RepoInfo(context.Background())
-			require.NoError(t, err, addr)
-			assert.Equal(t, tc.wantUser, user)
-			assert.Equal(t, tc.wantRepo, repo)
-		})
+		user, repo, err := g.RepoInfo(context.Background())
+		require.NoError(t, err, setup[0].addr)
+		assert.Equal(t, "arsham", user)
+		assert.Equal(t, "shark", repo)
+
+		g.Remote = setup[0].remote
+		user, repo, err = g.RepoInfo(context.Background())
+		require.NoError(t, err, setup[0].addr)
+		assert.Equal(t, "arsham", user)
+		assert.Equal(t, "shark", repo)
+
+		g.Remote = setup[1].remote
+		user, repo, err = g.RepoInfo(context.Background())
+		require.NoError(t, err, setup[0].addr)
+		assert.Equal(t, "arsham", user)
+		assert.Equal(t, "arshlib.nvim", repo)
 	}
 }
 
-func testGitRepoInfoRemote(t *testing.T) {
-	t.Parallel()
-	dir := createGitRepo(t)
-	g := commit.Git{
-		Dir: dir,
-	}
-
-	setup := []struct {
-		addr   string
-		remote string
-	}{{
-		addr:   "git@github.com:arsham/shark.git",
-		remote: "origin",
-	}, {
-		addr:   "git@github.com:arsham/arshlib.nvim.git",
-		remote: "other",
-	}}
-
-	for _, s := range setup {
-		args := []string{
-			"remote",
-			"add",
-			s.remote,
-			s.addr,
-		}
-		cmd := exec.CommandContext(context.Background(), "git", args...)
+func testGitHooksDir(backend commit.Backend) func(t *testing.T) {
+	return func(t *testing.T) {
+		t.Parallel()
+		ctx := context.Background()
+		dir := createGitRepo(t)
+		g := commit.Git{
+			Dir:     dir,
+			Backend: backend,
+		}
+
+		got, err := g.HooksDir(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, path.Join(dir, ".git", "hooks"), got)
+
+		cmd := exec.CommandContext(ctx, "git", "config", "core.hooksPath", "my-hooks")
 		cmd.Dir = dir
 		out, err := cmd.CombinedOutput()
 		require.NoError(t, err, string(out))
+
+		got, err = g.HooksDir(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, path.Join(dir, "my-hooks"), got)
 	}
+}
 
-	user, repo, err := g.RepoInfo(context.Background())
-	require.NoError(t, err, setup[0].addr)
-	assert.Equal(t, "arsham", user)
-	assert.Equal(t, "shark", repo)
+func testGitAnnotatedTags(backend commit.Backend) func(t *testing.T) {
+	return func(t *testing.T) {
+		t.Parallel()
+		ctx := context.Background()
+		dir := createGitRepo(t)
+		g := commit.Git{
+			Dir:     dir,
+			Backend: backend,
+		}
 
-	g.Remote = setup[0].remote
-	user, repo, err = g.RepoInfo(context.Background())
-	require.NoError(t, err, setup[0].addr)
-	assert.Equal(t, "arsham", user)
-	assert.Equal(t, "shark", repo)
+		createFile(t, dir, "file.txt", testament.RandomString(20))
+		commitChanges(t, dir, "msg1")
+		runGit(t, dir, "tag", "-a", "v0.0.1", "-m", "first")
+		appendToFile(t, dir, "file.txt", testament.RandomString(20))
+		commitChanges(t, dir, "msg2")
+		runGit(t, dir, "tag", "-a", "v0.0.2", "-m", "second")
+
+		got, err := g.LatestTag(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, "v0.0.2", got)
+
+		got, err = g.PreviousTag(ctx, "v0.0.2")
+		require.NoError(t, err)
+		assert.Equal(t, "v0.0.1", got)
+
+		logs, err := g.Commits(ctx, "v0.0.1", "v0.0.2")
+		require.NoError(t, err)
+		if diff := cmp.Diff([]string{"msg2"}, messages(logs), commitComparer...); diff != "" {
+			t.Errorf("(-want +got):\n%s", diff)
+		}
+	}
+}
 
-	g.Remote = setup[1].remote
-	user, repo, err = g.RepoInfo(context.Background())
-	require.NoError(t, err, setup[0].addr)
-	assert.Equal(t, "arsham", user)
-	assert.Equal(t, "arshlib.nvim", repo)
+func testGitTagAnnotation(backend commit.Backend) func(t *testing.T) {
+	return func(t *testing.T) {
+		t.Parallel()
+		ctx := context.Background()
+		dir := createGitRepo(t)
+		g := commit.Git{
+			Dir:     dir,
+			Backend: backend,
+		}
+
+		createFile(t, dir, "file.txt", testament.RandomString(20))
+		commitChanges(t, dir, "msg1")
+		createGitTag(t, dir, "v0.0.1")
+		msg := "A summary.\n\nMore details.\n-----BEGIN PGP SIGNATURE-----\n\niQEzBAABCAAdFiEE\n=abcd\n-----END PGP SIGNATURE-----\n"
+		runGit(t, dir, "-c", "user.name=Arsham Shirvani", "-c", "user.email=arsham@github.com",
+			"tag", "-a", "v0.0.2", "--cleanup=verbatim", "-m", msg)
+
+		got, err := g.TagAnnotation(ctx, "v0.0.1")
+		require.NoError(t, err)
+		assert.Nil(t, got)
+
+		got, err = g.TagAnnotation(ctx, "v0.0.2")
+		require.NoError(t, err)
+		require.NotNil(t, got)
+		assert.Equal(t, "A summary.\n\nMore details.", got.Message)
+		assert.Equal(t, "Arsham Shirvani <arsham@github.com>", got.Tagger)
+		assert.WithinDuration(t, time.Now(), got.Date, time.Minute)
+
+		_, err = g.TagAnnotation(ctx, "v0.0.3")
+		assert.Error(t, err)
+	}
+}
+
+func TestParseBackend(t *testing.T) {
+	t.Parallel()
+	tcs := map[string]commit.Backend{
+		"":     commit.BackendExec,
+		"exec": commit.BackendExec,
+		"Go":   commit.BackendGo,
+	}
+	for name, want := range tcs {
+		got, err := commit.ParseBackend(name)
+		require.NoError(t, err, name)
+		assert.Equal(t, want, got, name)
+	}
+	_, err := commit.ParseBackend("libgit2")
+	assert.Error(t, err)
+}
+
+func testGitErrors(backend commit.Backend) func(t *testing.T) {
+	return func(t *testing.T) {
+		t.Parallel()
+		ctx := context.Background()
+		empty := createGitRepo(t)
+		createFile(t, empty, "file.txt", testament.RandomString(20))
+		commitChanges(t, empty, testament.RandomString(20))
+
+		tagged := createGitRepo(t)
+		createFile(t, tagged, "file.txt", testament.RandomString(20))
+		commitChanges(t, tagged, testament.RandomString(20))
+		createGitTag(t, tagged, "v0.0.1")
+		createFile(t, tagged, "file2.txt", testament.RandomString(20))
+		commitChanges(t, tagged, testament.RandomString(20))
+		createGitTag(t, tagged, "v0.0.2")
+
+		untagged := createGitRepo(t)
+		createFile(t, untagged, "file.txt", testament.RandomString(20))
+		commitChanges(t, untagged, testament.RandomString(20))
+		createFile(t, untagged, "file2.txt", testament.RandomString(20))
+		commitChanges(t, untagged, testament.RandomString(20))
+		createGitTag(t, untagged, "v0.0.1")
+
+		latestTag := func(g commit.Git) error {
+			_, err := g.LatestTag(ctx)
+			return err
+		}
+		previousTag := func(tag string) func(g commit.Git) error {
+			return func(g commit.Git) error {
+				_, err := g.PreviousTag(ctx, tag)
+				return err
+			}
+		}
+		commits := func(from, to string) func(g commit.Git) error {
+			return func(g commit.Git) error {
+				_, err := g.Commits(ctx, from, to)
+				return err
+			}
+		}
+		repoInfo := func(g commit.Git) error {
+			_, _, err := g.RepoInfo(ctx)
+			return err
+		}
+
+		tcs := map[string]struct {
+			dir    string
+			remote string
+			fn     func(g commit.Git) error
+			want   error
+		}{
+			"not a repository latest tag":   {dir: t.TempDir(), fn: latestTag, want: commit.ErrNotRepository},
+			"not a repository previous tag": {dir: t.TempDir(), fn: previousTag("v0.0.1"), want: commit.ErrNotRepository},
+			"not a repository commits":      {dir: t.TempDir(), fn: commits("", "v0.0.1"), want: commit.ErrNotRepository},
+			"not a repository repo info":    {dir: t.TempDir(), fn: repoInfo, want: commit.ErrNotRepository},
+			"no tags":                       {dir: empty, fn: latestTag, want: commit.ErrNoTags},
+			"first tag on first commit":     {dir: tagged, fn: previousTag("v0.0.1"), want: commit.ErrNoTags},
+			"first tag":                     {dir: untagged, fn: previousTag("v0.0.1"), want: commit.ErrNoTags},
+			"unknown tag":                   {dir: tagged, fn: previousTag("v0.0.3"), want: commit.ErrTagNotFound},
+			"commits of unknown tag":        {dir: tagged, fn: commits("", "v0.0.3"), want: commit.ErrTagNotFound},
+			"commits from unknown tag":      {dir: tagged, fn: commits("v0.0.3", "v0.0.2"), want: commit.ErrTagNotFound},
+			"unknown remote":                {dir: tagged, remote: "upstream", fn: repoInfo, want: commit.ErrUnknownRemote},
+		}
+		all := []error{commit.ErrNotRepository, commit.ErrNoTags, commit.ErrTagNotFound, commit.ErrUnknownRemote}
+		for name, tc := range tcs {
+			tc := tc
+			t.Run(name, func(t *testing.T) {
+				t.Parallel()
+				g := commit.Git{
+					Dir:     tc.dir,
+					Remote:  tc.remote,
+					Backend: backend,
+				}
+				err := tc.fn(g)
+				require.Error(t, err)
+				var ge *commit.GitError
+				assert.True(t, errors.As(err, &ge), err)
+				for _, target := range all {
+					assert.Equal(t, target == tc.want, errors.Is(err, target), "%v: %v", target, err)
+				}
+			})
+		}
+	}
+}
+
+func testGitEachCommit(backend commit.Backend) func(t *testing.T) {
+	return func(t *testing.T) {
+		t.Parallel()
+		ctx := context.Background()
+		dir := createGitRepo(t)
+		date := time.Date(2022, 5, 14, 10, 0, 0, 0, time.FixedZone("", 2*60*60))
+		revParse := func() string {
+			t.Helper()
+			cmd := exec.CommandContext(ctx, "git", "rev-parse", "HEAD")
+			cmd.Dir = dir
+			out, err := cmd.Output()
+			require.NoError(t, err)
+			return strings.TrimSpace(string(out))
+		}
+
+		createFile(t, dir, "a.txt", testament.RandomString(20))
+		runGit(t, dir, "add", "-A")
+		runGit(t, dir, "commit", "--no-gpg-sign", "-m", "feat: first\n\nbody", "--author", "Jane Doe <jane@example.com>", "--date", date.Format(time.RFC3339))
+		first := revParse()
+		createGitTag(t, dir, "v0.0.1")
+
+		// The old separator of the commits in the log output.
+		zeros := strings.Repeat("0", 35)
+		msg := fmt.Sprintf("fix: %s in the title\n\nbody with %s\n\nCo-authored-by: John <john@example.com>\nSigned-off-by: arsham <arsham@github.com>\nRefs: #12\n  and #13\n", zeros, zeros)
+		createFile(t, dir, "b.txt", testament.RandomString(20))
+		createFile(t, dir, "c.txt", testament.RandomString(20))
+		commitChanges(t, dir, msg)
+		second := revParse()
+		createGitTag(t, dir, "v0.0.2")
+
+		g := commit.Git{
+			Dir:     dir,
+			Backend: backend,
+			Filter: commit.Filter{
+				Exclude: commit.Rules{Paths: []string{"docs/"}},
+			},
+		}
+		var got []commit.Commit
+		err := g.EachCommit(ctx, "", "v0.0.2", func(c commit.Commit) error {
+			got = append(got, c)
+			return nil
+		})
+		require.NoError(t, err)
+		require.Len(t, got, 2)
+
+		assert.Equal(t, second, got[0].Hash)
+		assert.Equal(t, []string{first}, got[0].Parents)
+		assert.Equal(t, "arsham", got[0].Author.Name)
+		assert.Equal(t, "arsham@github.com", got[0].Committer.Email)
+		assert.False(t, got[0].Committer.When.IsZero())
+		assert.Equal(t, msg, got[0].Message)
+		assert.Equal(t, []commit.Trailer{
+			{Key: "Co-authored-by", Value: "John <john@example.com>"},
+			{Key: "Signed-off-by", Value: "arsham <arsham@github.com>"},
+			{Key: "Refs", Value: "#12 and #13"},
+		}, got[0].Trailers)
+		assert.Equal(t, []string{"b.txt", "c.txt"}, got[0].Files)
+
+		assert.Equal(t, first, got[1].Hash)
+		assert.Empty(t, got[1].Parents)
+		assert.Equal(t, "Jane Doe <jane@example.com>", got[1].Author.String())
+		assert.True(t, date.Equal(got[1].Author.When), got[1].Author.When)
+		assert.Equal(t, "feat: first\n\nbody\n", got[1].Message)
+		assert.Empty(t, got[1].Trailers)
+		assert.Equal(t, []string{"a.txt"}, got[1].Files)
+
+		errStop := errors.New("stop")
+		var calls int
+		err = g.EachCommit(ctx, "", "v0.0.2", func(commit.Commit) error {
+			calls++
+			return errStop
+		})
+		assert.ErrorIs(t, err, errStop)
+		assert.Equal(t, 1, calls)
+
+		logs, err := g.Commits(ctx, "v0.0.1", "v0.0.2")
+		require.NoError(t, err)
+		assert.Equal(t, []string{msg}, messages(logs))
+	}
 }
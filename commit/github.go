@@ -0,0 +1,285 @@
+package commit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/github-release/github-release/github"
+	"github.com/kevinburke/rest/restclient"
+	"github.com/pkg/errors"
+	"github.com/tomnomnom/linkheader"
+)
+
+const baseURL = "https://api.github.com"
+
+// Release is a published release on GitHub.
+type Release struct {
+	ID      int64  `json:"id"`
+	TagName string `json:"tag_name"`
+	Name    string `json:"name"`
+	Body    string `json:"body"`
+	HTMLURL string `json:"html_url"`
+}
+
+// RateLimit is the state of the core API rate limit of the token.
+type RateLimit struct {
+	Reset     time.Time
+	Limit     int
+	Remaining int
+}
+
+type releaseCreate struct {
+	TagName         string `json:"tag_name"`
+	TargetCommitish string `json:"target_commitish,omitempty"`
+	Name            string `json:"name"`
+	Body            string `json:"body"`
+	Draft           bool   `json:"draft"`
+	Prerelease      bool   `json:"prerelease"`
+}
+
+// APICall describes a request to the GitHub API.
+type APICall struct {
+	Method  string
+	Path    string
+	Payload []byte
+}
+
+func (a APICall) String() string {
+	if len(a.Payload) == 0 {
+		return a.Method + " " + a.Path
+	}
+	return fmt.Sprintf("%s %s %s", a.Method, a.Path, a.Payload)
+}
+
+// retryAfter returns true if the response is a primary or secondary rate limit
+// error, with how long to wait before trying again.
+func retryAfter(resp *http.Response) (time.Duration, bool) {
+	if resp.StatusCode != http.StatusForbidden && resp.StatusCode != http.StatusTooManyRequests {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+		return time.Duration(secs) * time.Second, true
+	}
+	if resp.Header.Get("X-RateLimit-Remaining") != "0" {
+		return 0, false
+	}
+	reset, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64)
+	if err != nil {
+		return time.Minute, true
+	}
+	if wait := time.Until(time.Unix(reset, 0)); wait > 0 {
+		return wait, true
+	}
+	return 0, true
+}
+
+func (g Git) client(token, repo string) github.Client {
+	base := g.BaseURL
+	if base == "" {
+		base = baseURL
+	}
+	// The bearer authorization works with all kinds of tokens, and it is not
+	// sent if the token is empty.
+	rc := restclient.NewBearerClient(token, base)
+	rc.ErrorParser = parseError
+	if g.Client != nil {
+		rc.Client = g.Client
+	}
+	return github.NewClient(repo, token, rc)
+}
+
+// send makes the call, and discards the response.
+func (g Git) send(ctx context.Context, token, repo string, call APICall) error {
+	client := g.client(token, repo)
+	req, err := client.NewRequest(call.Method, call.Path, bytes.NewReader(call.Payload))
+	if err != nil {
+		return errors.Wrap(err, "creating request to the API")
+	}
+	resp, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+// getPages calls fn with the body of each page of the list at the path. The
+// pages are read one at a time, and it stops when the context is cancelled.
+func (g Git) getPages(ctx context.Context, token, repo, path string, fn func(io.Reader) error) error {
+	u, err := url.Parse(path)
+	if err != nil {
+		return errors.Wrapf(err, "parsing %s", path)
+	}
+	query := u.Query()
+	query.Set("per_page", "100")
+	u.RawQuery = query.Encode()
+
+	client := g.client(token, repo)
+	for next := u.String(); next != ""; {
+		req, err := client.NewRequest(http.MethodGet, next, nil)
+		if err != nil {
+			return errors.Wrap(err, "creating request to the API")
+		}
+		resp, err := client.Do(req.WithContext(ctx))
+		if err != nil {
+			return err
+		}
+		err = fn(resp.Body)
+		// nolint:errcheck // it's ok.
+		resp.Body.Close()
+		if err != nil {
+			return err
+		}
+
+		next = ""
+		for _, link := range linkheader.Parse(resp.Header.Get("Link")) {
+			if link.Rel == "next" {
+				next = link.URL
+			}
+		}
+	}
+	return nil
+}
+
+// newCall returns the call with v as its JSON payload. The v values are maps
+// of strings and numbers, which are always marshalled.
+func newCall(method, path string, v interface{}) APICall {
+	// nolint:errcheck // see above.
+	payload, _ := json.Marshal(v)
+	return APICall{Method: method, Path: path, Payload: payload}
+}
+
+// ReleaseCall returns the API call Release makes for publishing the release.
+func ReleaseCall(user, repo, tag, desc string) (APICall, error) {
+	params := releaseCreate{
+		TagName: tag,
+		Body:    desc,
+	}
+
+	payload, err := json.Marshal(params)
+	if err != nil {
+		return APICall{}, errors.Wrap(err, "marshalling values")
+	}
+	return APICall{
+		Method:  http.MethodPost,
+		Path:    fmt.Sprintf("/repos/%s/%s/releases", user, repo),
+		Payload: payload,
+	}, nil
+}
+
+// Release publishes the release for the user on the repo.
+func (g Git) Release(ctx context.Context, token, user, repo, tag, desc string) error {
+	call, err := ReleaseCall(user, repo, tag, desc)
+	if err != nil {
+		return err
+	}
+	payload := call.Payload
+
+	client := g.client(token, repo)
+	reader := bytes.NewReader(payload)
+	req, err := client.NewRequest(call.Method, call.Path, reader)
+	if err != nil {
+		return errors.Wrapf(err, "creating request to the API: %q", string(payload))
+	}
+	req = req.WithContext(ctx)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return errors.Wrapf(err, "publishing release of %s", tag)
+	}
+	// nolint:errcheck // it's ok.
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		return fmt.Errorf("publishing release of %s: unexpected status %s", tag, resp.Status)
+	}
+	return nil
+}
+
+// Releases returns all releases of the user's repo.
+func (g Git) Releases(ctx context.Context, token, user, repo string) ([]Release, error) {
+	var releases []Release
+	err := g.getPages(ctx, token, repo, fmt.Sprintf("/repos/%s/%s/releases", user, repo), func(r io.Reader) error {
+		var page []Release
+		if err := json.NewDecoder(r).Decode(&page); err != nil {
+			return errors.Wrap(err, "decoding releases")
+		}
+		releases = append(releases, page...)
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "listing releases")
+	}
+	return releases, nil
+}
+
+// ReleaseByTag returns the release of the tag. It returns nil if the tag has no
+// release.
+func (g Git) ReleaseByTag(ctx context.Context, token, user, repo, tag string) (*Release, error) {
+	client := g.client(token, repo)
+	req, err := client.NewRequest("GET", fmt.Sprintf("/repos/%s/%s/releases/tags/%s", user, repo, url.PathEscape(tag)), nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "creating request to the API")
+	}
+	req = req.WithContext(ctx)
+
+	resp, err := client.Do(req)
+	var ae *APIError
+	if errors.As(err, &ae) && ae.Code == http.StatusNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "getting release of %s", tag)
+	}
+	// nolint:errcheck // it's ok.
+	defer resp.Body.Close()
+
+	release := &Release{}
+	if err := json.NewDecoder(resp.Body).Decode(release); err != nil {
+		return nil, errors.Wrap(err, "decoding release")
+	}
+	return release, nil
+}
+
+// RateLimit returns the current state of the core rate limit for the token.
+// Querying the rate limit does not count against it.
+func (g Git) RateLimit(ctx context.Context, token string) (RateLimit, error) {
+	client := g.client(token, "")
+	req, err := client.NewRequest("GET", "/rate_limit", nil)
+	if err != nil {
+		return RateLimit{}, errors.Wrap(err, "creating request to the API")
+	}
+	req = req.WithContext(ctx)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return RateLimit{}, errors.Wrap(err, "getting rate limit")
+	}
+	// nolint:errcheck // it's ok.
+	defer resp.Body.Close()
+
+	var v struct {
+		Resources struct {
+			Core struct {
+				Limit     int   `json:"limit"`
+				Remaining int   `json:"remaining"`
+				Reset     int64 `json:"reset"`
+			} `json:"core"`
+		} `json:"resources"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&v); err != nil {
+		return RateLimit{}, errors.Wrap(err, "decoding rate limit")
+	}
+	core := v.Resources.Core
+	return RateLimit{
+		Limit:     core.Limit,
+		Remaining: core.Remaining,
+		Reset:     time.Unix(core.Reset, 0),
+	}, nil
+}
@@ -0,0 +1,153 @@
+package commit_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/arsham/gitrelease/commit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGitHub(t *testing.T) {
+	t.Parallel()
+	t.Run("Release", testGitHubRelease)
+	t.Run("Releases", testGitHubReleases)
+	t.Run("ReleasesCancel", testGitHubReleasesCancel)
+	t.Run("ReleaseByTag", testGitHubReleaseByTag)
+	t.Run("RateLimit", testGitHubRateLimit)
+	t.Run("Anonymous", testGitHubAnonymous)
+}
+
+func testGitHubAnonymous(t *testing.T) {
+	t.Parallel()
+	var auth []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = append(auth, r.Header.Get("Authorization"))
+		fmt.Fprint(w, `{"id": 1, "tag_name": "v0.0.1"}`)
+	}))
+	t.Cleanup(srv.Close)
+
+	g := commit.Git{BaseURL: srv.URL}
+	_, err := g.ReleaseByTag(context.Background(), "", "arsham", "gitrelease", "v0.0.1")
+	require.NoError(t, err)
+	_, err = g.ReleaseByTag(context.Background(), "token", "arsham", "gitrelease", "v0.0.1")
+	require.NoError(t, err)
+	require.Len(t, auth, 2)
+	assert.Empty(t, auth[0])
+	assert.NotEmpty(t, auth[1])
+}
+
+func testGitHubRelease(t *testing.T) {
+	t.Parallel()
+	var got map[string]interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/repos/arsham/gitrelease/releases", r.URL.Path)
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, `{}`)
+	}))
+	t.Cleanup(srv.Close)
+
+	g := commit.Git{BaseURL: srv.URL}
+	err := g.Release(context.Background(), "token", "arsham", "gitrelease", "v0.1.0", "desc")
+	require.NoError(t, err)
+	assert.Equal(t, "v0.1.0", got["tag_name"])
+	assert.Equal(t, "desc", got["body"])
+}
+
+func testGitHubReleases(t *testing.T) {
+	t.Parallel()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/repos/arsham/gitrelease/releases", r.URL.Path)
+		if r.URL.Query().Get("page") == "2" {
+			fmt.Fprint(w, `[{"id": 3, "tag_name": "v0.0.1"}]`)
+			return
+		}
+		w.Header().Set("Link", fmt.Sprintf(`<http://%s%s?page=2>; rel="next"`, r.Host, r.URL.Path))
+		fmt.Fprint(w, `[{"id": 1, "tag_name": "v0.0.3", "body": "desc"}, {"id": 2, "tag_name": "v0.0.2"}]`)
+	}))
+	t.Cleanup(srv.Close)
+
+	g := commit.Git{BaseURL: srv.URL}
+	got, err := g.Releases(context.Background(), "token", "arsham", "gitrelease")
+	require.NoError(t, err)
+	want := []commit.Release{
+		{ID: 1, TagName: "v0.0.3", Body: "desc"},
+		{ID: 2, TagName: "v0.0.2"},
+		{ID: 3, TagName: "v0.0.1"},
+	}
+	assert.Equal(t, want, got)
+}
+
+func testGitHubReleasesCancel(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var pages []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		pages = append(pages, r.URL.Query().Get("page"))
+		// The next page should not be read after the cancellation.
+		cancel()
+		w.Header().Set("Link", fmt.Sprintf(`<http://%s%s?page=2>; rel="next"`, r.Host, r.URL.Path))
+		fmt.Fprint(w, `[{"id": 1, "tag_name": "v0.0.2"}]`)
+	}))
+	t.Cleanup(srv.Close)
+
+	g := commit.Git{BaseURL: srv.URL}
+	_, err := g.Releases(ctx, "token", "arsham", "gitrelease")
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, []string{""}, pages)
+}
+
+func testGitHubReleaseByTag(t *testing.T) {
+	t.Parallel()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/arsham/gitrelease/releases/tags/v0.0.1":
+			fmt.Fprint(w, `{"id": 1, "tag_name": "v0.0.1", "body": "desc"}`)
+		case "/repos/arsham/gitrelease/releases/tags/v0.0.2":
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"message": "Not Found"}`)
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	g := commit.Git{BaseURL: srv.URL}
+	ctx := context.Background()
+	got, err := g.ReleaseByTag(ctx, "token", "arsham", "gitrelease", "v0.0.1")
+	require.NoError(t, err)
+	assert.Equal(t, &commit.Release{ID: 1, TagName: "v0.0.1", Body: "desc"}, got)
+
+	got, err = g.ReleaseByTag(ctx, "token", "arsham", "gitrelease", "v0.0.2")
+	require.NoError(t, err)
+	assert.Nil(t, got)
+
+	_, err = g.ReleaseByTag(ctx, "token", "arsham", "gitrelease", "v0.0.3")
+	assert.Error(t, err)
+}
+
+func testGitHubRateLimit(t *testing.T) {
+	t.Parallel()
+	reset := time.Now().Add(time.Hour).Truncate(time.Second)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/rate_limit", r.URL.Path)
+		fmt.Fprintf(w, `{"resources": {"core": {"limit": 5000, "remaining": 42, "reset": %d}}}`, reset.Unix())
+	}))
+	t.Cleanup(srv.Close)
+
+	g := commit.Git{BaseURL: srv.URL}
+	got, err := g.RateLimit(context.Background(), "token")
+	require.NoError(t, err)
+	assert.Equal(t, 5000, got.Limit)
+	assert.Equal(t, 42, got.Remaining)
+	assert.True(t, reset.Equal(got.Reset))
+}
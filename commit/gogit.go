@@ -0,0 +1,405 @@
+package commit
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+	"github.com/go-git/go-git/v5/storage/filesystem"
+	"github.com/pkg/errors"
+)
+
+// GoGitRepository reads the repository in the Dir, or any of its parents,
+// without running the git binary. If Dir is empty, the current folder is
+// used.
+type GoGitRepository struct {
+	Dir string
+}
+
+func (r GoGitRepository) open() (*git.Repository, error) {
+	dir := r.Dir
+	if dir == "" {
+		dir = "."
+	}
+	repo, err := git.PlainOpenWithOptions(dir, &git.PlainOpenOptions{DetectDotGit: true})
+	if errors.Is(err, git.ErrRepositoryNotExists) {
+		return nil, &GitError{Op: "opening repository in " + dir, Err: err, Kind: ErrNotRepository}
+	}
+	return repo, errors.Wrapf(err, "opening repository in %s", dir)
+}
+
+// resolve returns the hash of the commit of the revision.
+func resolve(repo *git.Repository, rev string) (*plumbing.Hash, error) {
+	hash, err := repo.ResolveRevision(plumbing.Revision(rev))
+	if err != nil {
+		return nil, &GitError{Op: "resolving " + rev, Err: err, Kind: ErrTagNotFound}
+	}
+	return hash, nil
+}
+
+// LatestTag returns the closest tag reachable from HEAD.
+func (r GoGitRepository) LatestTag(ctx context.Context) (string, error) {
+	return r.describe(ctx, "HEAD")
+}
+
+// PreviousTag returns the closest tag reachable from the parent of the tag.
+func (r GoGitRepository) PreviousTag(ctx context.Context, tag string) (string, error) {
+	if tag == "@" {
+		tag = "HEAD"
+	}
+	prev, err := r.describe(ctx, tag+"^")
+	var ge *GitError
+	if errors.As(err, &ge) && ge.Kind == ErrTagNotFound {
+		// The tag can be on the first commit, which has no parents.
+		repo, oerr := r.open()
+		if oerr != nil {
+			return "", oerr
+		}
+		if _, rerr := resolve(repo, tag); rerr == nil {
+			ge.Kind = ErrNoTags
+		}
+	}
+	return prev, err
+}
+
+// describe returns the tag of the newest commit reachable from the revision.
+// If there are more than one tag on the commit, the one with the highest
+// version is returned.
+func (r GoGitRepository) describe(ctx context.Context, rev string) (string, error) {
+	repo, err := r.open()
+	if err != nil {
+		return "", err
+	}
+	hash, err := resolve(repo, rev)
+	if err != nil {
+		return "", err
+	}
+	tags, err := commitTags(repo)
+	if err != nil {
+		return "", err
+	}
+	if len(tags) == 0 {
+		return "", &GitError{
+			Op:   "describing " + rev,
+			Err:  errors.New("no names found, cannot describe anything"),
+			Kind: ErrNoTags,
+		}
+	}
+
+	iter, err := repo.Log(&git.LogOptions{From: *hash, Order: git.LogOrderCommitterTime})
+	if err != nil {
+		return "", errors.Wrap(err, "reading log")
+	}
+	defer iter.Close()
+	var found string
+	err = iter.ForEach(func(c *object.Commit) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		names, ok := tags[c.Hash]
+		if !ok {
+			return nil
+		}
+		sortVersions(names)
+		found = names[len(names)-1]
+		return storer.ErrStop
+	})
+	if err != nil {
+		return "", err
+	}
+	if found == "" {
+		return "", &GitError{
+			Op:   "describing " + rev,
+			Err:  fmt.Errorf("no tags can describe %s", rev),
+			Kind: ErrNoTags,
+		}
+	}
+	return found, nil
+}
+
+// commitTags returns the names of the tags of each commit.
+func commitTags(repo *git.Repository) (map[plumbing.Hash][]string, error) {
+	iter, err := repo.Tags()
+	if err != nil {
+		return nil, errors.Wrap(err, "listing tags")
+	}
+	defer iter.Close()
+	tags := make(map[plumbing.Hash][]string)
+	err = iter.ForEach(func(ref *plumbing.Reference) error {
+		hash := ref.Hash()
+		// Annotated tags point to the tag objects.
+		if tag, err := repo.TagObject(hash); err == nil {
+			c, err := tag.Commit()
+			if err != nil {
+				// The tag is not on a commit.
+				return nil
+			}
+			hash = c.Hash
+		}
+		tags[hash] = append(tags[hash], ref.Name().Short())
+		return nil
+	})
+	return tags, errors.Wrap(err, "reading tags")
+}
+
+// Tags returns all tags sorted by their version.
+func (r GoGitRepository) Tags(ctx context.Context) ([]string, error) {
+	repo, err := r.open()
+	if err != nil {
+		return nil, err
+	}
+	iter, err := repo.Tags()
+	if err != nil {
+		return nil, errors.Wrap(err, "listing tags")
+	}
+	defer iter.Close()
+	var tags []string
+	err = iter.ForEach(func(ref *plumbing.Reference) error {
+		tags = append(tags, ref.Name().Short())
+		return ctx.Err()
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "reading tags")
+	}
+	sortVersions(tags)
+	return tags, nil
+}
+
+// sortVersions sorts the names by the numbers in them, and then
+// alphabetically.
+func sortVersions(names []string) {
+	sort.SliceStable(names, func(i, j int) bool {
+		if c := compareVersions(names[i], names[j]); c != 0 {
+			return c < 0
+		}
+		return names[i] < names[j]
+	})
+}
+
+// Log calls fn with the commits between from and to. Only the hashes of the
+// commits are kept for sorting them, and each commit is read again when fn is
+// called.
+func (r GoGitRepository) Log(ctx context.Context, from, to string, opts LogOptions, fn func(Commit) error) error {
+	repo, err := r.open()
+	if err != nil {
+		return err
+	}
+	toHash, err := resolve(repo, to)
+	if err != nil {
+		return err
+	}
+	excluded := make(map[plumbing.Hash]struct{})
+	if from != "" {
+		fromHash, err := resolve(repo, from)
+		if err != nil {
+			return err
+		}
+		err = walkCommits(ctx, repo, *fromHash, false, excluded, func(*object.Commit) error { return nil })
+		if err != nil {
+			return err
+		}
+	}
+
+	type commitTime struct {
+		hash plumbing.Hash
+		when time.Time
+	}
+	var commits []commitTime
+	err = walkCommits(ctx, repo, *toHash, opts.FirstParent, excluded, func(c *object.Commit) error {
+		if opts.NoMerges && c.NumParents() > 1 {
+			return nil
+		}
+		commits = append(commits, commitTime{hash: c.Hash, when: c.Committer.When})
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	sort.SliceStable(commits, func(i, j int) bool {
+		return commits[i].when.After(commits[j].when)
+	})
+
+	for _, ct := range commits {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		c, err := repo.CommitObject(ct.hash)
+		if err != nil {
+			return errors.Wrapf(err, "reading commit %s", ct.hash)
+		}
+		parents := make([]string, 0, len(c.ParentHashes))
+		for _, p := range c.ParentHashes {
+			parents = append(parents, p.String())
+		}
+		commit := Commit{
+			Hash:      c.Hash.String(),
+			Parents:   parents,
+			Author:    Signature{Name: c.Author.Name, Email: c.Author.Email, When: c.Author.When},
+			Committer: Signature{Name: c.Committer.Name, Email: c.Committer.Email, When: c.Committer.When},
+			Message:   c.Message,
+			Trailers:  ParseTrailers(c.Message),
+		}
+		if opts.Files {
+			commit.Files, err = changedFiles(c)
+			if err != nil {
+				return err
+			}
+		}
+		if err := fn(commit); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// walkCommits calls fn with each commit reachable from the hash that is not
+// in the seen set, and adds them to the set. If firstParent is true, only the
+// first parent of the merge commits are followed.
+func walkCommits(ctx context.Context, repo *git.Repository, hash plumbing.Hash, firstParent bool, seen map[plumbing.Hash]struct{}, fn func(*object.Commit) error) error {
+	stack := []plumbing.Hash{hash}
+	for len(stack) > 0 {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		h := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if _, ok := seen[h]; ok {
+			continue
+		}
+		seen[h] = struct{}{}
+		c, err := repo.CommitObject(h)
+		if err != nil {
+			return errors.Wrapf(err, "reading commit %s", h)
+		}
+		if err := fn(c); err != nil {
+			return err
+		}
+		parents := c.ParentHashes
+		if firstParent && len(parents) > 1 {
+			parents = parents[:1]
+		}
+		stack = append(stack, parents...)
+	}
+	return nil
+}
+
+// changedFiles returns the files the commit changes compared to its first
+// parent.
+func changedFiles(c *object.Commit) ([]string, error) {
+	tree, err := c.Tree()
+	if err != nil {
+		return nil, errors.Wrapf(err, "reading tree of %s", c.Hash)
+	}
+	var parentTree *object.Tree
+	if c.NumParents() > 0 {
+		parent, err := c.Parent(0)
+		if err != nil {
+			return nil, errors.Wrapf(err, "reading parent of %s", c.Hash)
+		}
+		if parentTree, err = parent.Tree(); err != nil {
+			return nil, errors.Wrapf(err, "reading tree of %s", parent.Hash)
+		}
+	}
+	changes, err := object.DiffTree(parentTree, tree)
+	if err != nil {
+		return nil, errors.Wrapf(err, "comparing %s with its parent", c.Hash)
+	}
+	files := make([]string, 0, len(changes))
+	for _, change := range changes {
+		name := change.To.Name
+		if name == "" {
+			name = change.From.Name
+		}
+		files = append(files, name)
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// TagAnnotation returns the annotation of the tag, or nil if the tag is not
+// annotated.
+func (r GoGitRepository) TagAnnotation(_ context.Context, tag string) (*Annotation, error) {
+	repo, err := r.open()
+	if err != nil {
+		return nil, err
+	}
+	ref, err := repo.Tag(tag)
+	if err != nil {
+		return nil, errors.Wrapf(err, "getting tag %s", tag)
+	}
+	t, err := repo.TagObject(ref.Hash())
+	if errors.Is(err, plumbing.ErrObjectNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "reading tag %s", tag)
+	}
+	return &Annotation{
+		Tagger:  fmt.Sprintf("%s <%s>", t.Tagger.Name, t.Tagger.Email),
+		Date:    t.Tagger.When,
+		Message: strings.TrimSpace(t.Message),
+	}, nil
+}
+
+// RemoteURL returns the first address of the remote.
+func (r GoGitRepository) RemoteURL(_ context.Context, name string) (string, error) {
+	repo, err := r.open()
+	if err != nil {
+		return "", err
+	}
+	remote, err := repo.Remote(name)
+	if errors.Is(err, git.ErrRemoteNotFound) {
+		return "", &GitError{Op: "getting remote " + name, Err: err, Kind: ErrUnknownRemote}
+	}
+	if err != nil {
+		return "", errors.Wrapf(err, "getting remote %s", name)
+	}
+	urls := remote.Config().URLs
+	if len(urls) == 0 {
+		return "", fmt.Errorf("remote %s has no url", name)
+	}
+	return urls[0], nil
+}
+
+// GitPath returns the absolute path of the name inside the git directory. The
+// hooks path respects the core.hooksPath setting.
+func (r GoGitRepository) GitPath(_ context.Context, name string) (string, error) {
+	repo, err := r.open()
+	if err != nil {
+		return "", err
+	}
+	storage, ok := repo.Storer.(*filesystem.Storage)
+	if !ok {
+		return "", errors.New("the repository is not on the file system")
+	}
+	gitDir, err := filepath.Abs(storage.Filesystem().Root())
+	if err != nil {
+		return "", errors.Wrap(err, "getting the git directory")
+	}
+
+	if name == "hooks" {
+		cfg, err := repo.Config()
+		if err != nil {
+			return "", errors.Wrap(err, "reading config")
+		}
+		if hooks := cfg.Raw.Section("core").Option("hooksPath"); hooks != "" {
+			if filepath.IsAbs(hooks) {
+				return hooks, nil
+			}
+			// Relative paths are resolved from the top of the work tree.
+			top := gitDir
+			if wt, err := repo.Worktree(); err == nil {
+				top = wt.Filesystem.Root()
+			}
+			return filepath.Join(top, hooks), nil
+		}
+	}
+	return filepath.Join(gitDir, name), nil
+}
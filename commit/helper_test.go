@@ -10,6 +10,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/arsham/gitrelease/commit"
 	"github.com/google/go-cmp/cmp"
 	"github.com/stretchr/testify/require"
 )
@@ -52,8 +53,7 @@ func createGitTag(t *testing.T, dir, tag string) {
 
 func createFile(t *testing.T, dir, filename, content string) {
 	t.Helper()
-	require.NoError(t, os.Chdir(dir))
-	require.NoError(t, os.WriteFile(filename, []byte(content), 0o644))
+	require.NoError(t, os.WriteFile(path.Join(dir, filename), []byte(content), 0o644))
 }
 
 func commitChanges(t *testing.T, dir, msg string) {
@@ -72,10 +72,17 @@ func commitChanges(t *testing.T, dir, msg string) {
 	require.NoError(t, err, string(out))
 }
 
+func runGit(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	cmd := exec.CommandContext(context.Background(), "git", args...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(out))
+}
+
 func appendToFile(t *testing.T, dir, filename, msg string) {
 	t.Helper()
-	require.NoError(t, os.Chdir(dir))
-	f, err := os.OpenFile(filename, os.O_APPEND|os.O_WRONLY|os.O_CREATE, 0o600)
+	f, err := os.OpenFile(path.Join(dir, filename), os.O_APPEND|os.O_WRONLY|os.O_CREATE, 0o600)
 	require.NoError(t, err)
 	defer f.Close()
 
@@ -102,3 +109,21 @@ var commitComparer = cmp.Options{
 	cmpIgnoreNewlines,
 	stringSliceCleaner,
 }
+
+// toCommits returns the commits with the messages.
+func toCommits(msgs []string) []commit.Commit {
+	ret := make([]commit.Commit, 0, len(msgs))
+	for _, msg := range msgs {
+		ret = append(ret, commit.Commit{Message: msg})
+	}
+	return ret
+}
+
+// messages returns the messages of the commits.
+func messages(commits []commit.Commit) []string {
+	ret := make([]string, 0, len(commits))
+	for _, c := range commits {
+		ret = append(ret, c.Message)
+	}
+	return ret
+}
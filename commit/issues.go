@@ -0,0 +1,153 @@
+package commit
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// TicketsVerb is the section the summary of the issue keys is listed in.
+const TicketsVerb = "Tickets"
+
+// IssueTracker finds the keys of an issue tracker in the commits, e.g.
+// PLAT-1234 for Jira, and links them.
+type IssueTracker struct {
+	// Patterns match the issue keys, e.g. `PLAT-\d+`.
+	Patterns []*regexp.Regexp
+	// URL is the address of the issues. The {key} placeholder is replaced
+	// with the key, otherwise the key is appended to the URL. If empty, the
+	// keys are not linked.
+	URL string
+	// Summary lists all the keys in the TicketsVerb section.
+	Summary bool
+
+	// wordRe matches the keys of all Patterns as whole words.
+	wordRe *regexp.Regexp
+}
+
+// WithIssueTracker links the issue keys found in the scope, title or body of
+// the commits. The keys in the title are linked in place, and the rest are
+// appended to the entry.
+func WithIssueTracker(t IssueTracker) Option {
+	patterns := make([]string, 0, len(t.Patterns))
+	for _, re := range t.Patterns {
+		patterns = append(patterns, "(?:"+re.String()+")")
+	}
+	// The patterns are valid, therefore their alternation is valid too.
+	t.wordRe = regexp.MustCompile(`\b(?:` + strings.Join(patterns, "|") + `)\b`)
+	return func(o *options) {
+		o.tracker = &t
+	}
+}
+
+// keys returns the unique issue keys in the message, in the order they
+// appear.
+func (t IssueTracker) keys(msg string) []string {
+	type match struct {
+		key string
+		pos int
+	}
+	var matches []match
+	for _, re := range t.Patterns {
+		for _, loc := range re.FindAllStringIndex(msg, -1) {
+			matches = append(matches, match{key: msg[loc[0]:loc[1]], pos: loc[0]})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].pos < matches[j].pos
+	})
+
+	keys := make([]string, 0, len(matches))
+	seen := make(map[string]struct{}, len(matches))
+	for _, m := range matches {
+		if _, ok := seen[m.key]; ok {
+			continue
+		}
+		seen[m.key] = struct{}{}
+		keys = append(keys, m.key)
+	}
+	return keys
+}
+
+func (t IssueTracker) link(key string) string {
+	if t.URL == "" {
+		return key
+	}
+	if strings.Contains(t.URL, "{key}") {
+		return fmt.Sprintf("[%s](%s)", key, strings.ReplaceAll(t.URL, "{key}", key))
+	}
+	return fmt.Sprintf("[%s](%s%s)", key, t.URL, key)
+}
+
+// apply removes the keys from the scopes of the group, links the keys in its
+// description and appends the rest of the keys found in the message.
+func (t IssueTracker) apply(g *Group, msg string) []string {
+	keys := t.keys(msg)
+	if len(keys) == 0 {
+		return nil
+	}
+
+	if g.Subject != "" {
+		scopes := strings.Split(g.Subject, ",")
+		kept := scopes[:0]
+		for _, scope := range scopes {
+			if !t.isKey(scope) {
+				kept = append(kept, scope)
+			}
+		}
+		g.Subject = strings.Join(kept, ",")
+	}
+
+	found := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		found[key] = false
+	}
+	g.Description = t.wordRe.ReplaceAllStringFunc(g.Description, func(key string) string {
+		if _, ok := found[key]; !ok {
+			return key
+		}
+		found[key] = true
+		return t.link(key)
+	})
+	var rest []string
+	for _, key := range keys {
+		if !found[key] {
+			rest = append(rest, t.link(key))
+		}
+	}
+	if len(rest) > 0 {
+		g.Description = fmt.Sprintf("%s (%s)", g.Description, strings.Join(rest, ", "))
+	}
+	return keys
+}
+
+func (t IssueTracker) isKey(s string) bool {
+	for _, re := range t.Patterns {
+		if loc := re.FindStringIndex(s); loc != nil && loc[0] == 0 && loc[1] == len(s) {
+			return true
+		}
+	}
+	return false
+}
+
+// summary returns the groups for listing the keys in the TicketsVerb section.
+func (t IssueTracker) summary(keys []string) []Group {
+	sorted := make([]string, len(keys))
+	copy(sorted, keys)
+	sort.Strings(sorted)
+
+	groups := make([]Group, 0, len(sorted))
+	seen := make(map[string]struct{}, len(sorted))
+	for _, key := range sorted {
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		groups = append(groups, Group{
+			Verb:        TicketsVerb,
+			Description: t.link(key),
+		})
+	}
+	return groups
+}
@@ -0,0 +1,88 @@
+package commit_test
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/arsham/gitrelease/commit"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestIssueTracker(t *testing.T) {
+	t.Parallel()
+	patterns := []*regexp.Regexp{
+		regexp.MustCompile(`PLAT-\d+`),
+		regexp.MustCompile(`OPS-\d+`),
+	}
+	tcs := map[string]struct {
+		tracker commit.IssueTracker
+		logs    []string
+		want    string
+	}{
+		"no keys": {
+			tracker: commit.IssueTracker{Patterns: patterns, URL: "https://jira.example.com/browse/"},
+			logs:    []string{"fix(api): something"},
+			want:    "### Fix\n\n- **Api:** Something",
+		},
+		"scope": {
+			tracker: commit.IssueTracker{Patterns: patterns, URL: "https://jira.example.com/browse/"},
+			logs:    []string{"fix(PLAT-12): something", "fix(api,OPS-3): other"},
+			want: "### Fix\n\n" +
+				"- Something ([PLAT-12](https://jira.example.com/browse/PLAT-12))\n" +
+				"- **Api:** Other ([OPS-3](https://jira.example.com/browse/OPS-3))",
+		},
+		"title": {
+			tracker: commit.IssueTracker{Patterns: patterns, URL: "https://jira.example.com/browse/{key}/details"},
+			logs:    []string{"fix: handle PLAT-12 case"},
+			want:    "### Fix\n\n- Handle [PLAT-12](https://jira.example.com/browse/PLAT-12/details) case",
+		},
+		"body": {
+			tracker: commit.IssueTracker{Patterns: patterns, URL: "https://jira.example.com/browse/"},
+			logs:    []string{"fix: something\n\nRefs: PLAT-12, OPS-3\nSee PLAT-12 too"},
+			want: "### Fix\n\n- Something (" +
+				"[PLAT-12](https://jira.example.com/browse/PLAT-12), " +
+				"[OPS-3](https://jira.example.com/browse/OPS-3))",
+		},
+		"overlapping patterns": {
+			tracker: commit.IssueTracker{
+				Patterns: append([]*regexp.Regexp{regexp.MustCompile(`[A-Z]+-\d+`)}, patterns...),
+				URL:      "https://jira.example.com/browse/",
+			},
+			logs: []string{"fix: handle PLAT-12 and PLAT-123 cases"},
+			want: "### Fix\n\n- Handle [PLAT-12](https://jira.example.com/browse/PLAT-12) and " +
+				"[PLAT-123](https://jira.example.com/browse/PLAT-123) cases",
+		},
+		"no url": {
+			tracker: commit.IssueTracker{Patterns: patterns},
+			logs:    []string{"fix: something\n\nPLAT-12"},
+			want:    "### Fix\n\n- Something (PLAT-12)",
+		},
+		"summary": {
+			tracker: commit.IssueTracker{Patterns: patterns, URL: "https://jira.example.com/browse/", Summary: true},
+			logs:    []string{"fix(PLAT-12): something", "feat: other\n\nOPS-3 PLAT-12"},
+			want: "### Feature\n\n- Other (" +
+				"[OPS-3](https://jira.example.com/browse/OPS-3), " +
+				"[PLAT-12](https://jira.example.com/browse/PLAT-12))\n\n\n" +
+				"### Fix\n\n- Something ([PLAT-12](https://jira.example.com/browse/PLAT-12))\n\n\n" +
+				"### Tickets\n\n" +
+				"- [OPS-3](https://jira.example.com/browse/OPS-3)\n" +
+				"- [PLAT-12](https://jira.example.com/browse/PLAT-12)",
+		},
+	}
+
+	for name, tc := range tcs {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			got := commit.ParseGroups(toCommits(tc.logs), commit.WithIssueTracker(tc.tracker))
+			gotS := strings.Split(got, "\n\n\n")
+			sort.Strings(gotS)
+			wantS := strings.Split(tc.want, "\n\n\n")
+			if diff := cmp.Diff(wantS, gotS); diff != "" {
+				t.Errorf("(-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,120 @@
+package commit
+
+import (
+	"fmt"
+	"strings"
+)
+
+// A Diagnostic is a problem found on a line of a commit message. Lines start
+// from 1.
+type Diagnostic struct {
+	Message string
+	Line    int
+}
+
+func (d Diagnostic) String() string {
+	return fmt.Sprintf("%d: %s", d.Line, d.Message)
+}
+
+// Linter checks commit messages against the conventions used for grouping
+// them in the release notes.
+type Linter struct {
+	// Convention is used for parsing the messages. The default is
+	// ConventionalCommits.
+	Convention Convention
+	// Verbs restricts the sections commits can be listed in, e.g. "Feature" or
+	// "Fix". If empty, all known verbs are allowed.
+	Verbs []string
+	// Scopes restricts the scopes a commit can have. If empty, any scope is
+	// allowed.
+	Scopes []string
+}
+
+// Lint returns the problems found in the commit message. It returns nil if
+// the message is valid.
+func (l Linter) Lint(msg string) []Diagnostic {
+	lines := strings.Split(strings.TrimLeft(msg, "\n"), "\n")
+	diags := l.lintTitle(lines[0])
+	if len(lines) > 1 && strings.TrimSpace(lines[1]) != "" {
+		diags = append(diags, Diagnostic{
+			Line:    2,
+			Message: "the title should be separated from the body by a blank line",
+		})
+	}
+	return diags
+}
+
+func (l Linter) lintTitle(title string) []Diagnostic {
+	var diags []Diagnostic
+	report := func(format string, args ...interface{}) {
+		diags = append(diags, Diagnostic{
+			Line:    1,
+			Message: fmt.Sprintf(format, args...),
+		})
+	}
+
+	if strings.TrimSpace(title) == "" {
+		report("empty commit title")
+		return diags
+	}
+	var group Group
+	switch c := l.Convention.(type) {
+	case nil, ConventionalCommits:
+		matches := descRe.FindStringSubmatch(title)
+		if matches == nil {
+			report("the title doesn't start with a type, it will be listed under Misc")
+			return diags
+		}
+		group = GroupFromCommit(title)
+		if group.Verb == "Misc" {
+			report("unknown type %q, it will be listed under Misc", strings.TrimSuffix(matches[1], "!"))
+			return diags
+		}
+	default:
+		group = c.Group(title)
+		if group.Verb == "Misc" {
+			report("unknown convention prefix, it will be listed under Misc")
+			return diags
+		}
+	}
+	if len(l.Verbs) > 0 && !containsFold(l.Verbs, group.Verb) {
+		report("type %q is not allowed", group.Verb)
+	}
+	if len(l.Scopes) > 0 && group.Subject != "" {
+		for _, scope := range strings.Split(group.Subject, ",") {
+			if !containsFold(l.Scopes, scope) {
+				report("scope %q is not allowed", scope)
+			}
+		}
+	}
+	if group.Description == "" || group.Description == strings.TrimSpace(title) {
+		report("missing description after the type")
+	}
+	return diags
+}
+
+// CleanMessage removes the comment lines and everything below the scissors
+// line from a message written by git for the commit-msg hook.
+func CleanMessage(msg string) string {
+	lines := strings.Split(msg, "\n")
+	ret := make([]string, 0, len(lines))
+	for _, line := range lines {
+		if strings.HasPrefix(line, "# ------------------------ >8 ------------------------") {
+			break
+		}
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+		ret = append(ret, line)
+	}
+	return strings.TrimSpace(strings.Join(ret, "\n"))
+}
+
+func containsFold(list []string, s string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,69 @@
+package commit_test
+
+import (
+	"testing"
+
+	"github.com/arsham/gitrelease/commit"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestLinter(t *testing.T) {
+	t.Parallel()
+	t.Run("Lint", testLinterLint)
+	t.Run("CleanMessage", testLinterCleanMessage)
+}
+
+func testLinterLint(t *testing.T) {
+	t.Parallel()
+	diag := func(line int, msg string) []commit.Diagnostic {
+		return []commit.Diagnostic{{Line: line, Message: msg}}
+	}
+	tcs := map[string]struct {
+		linter commit.Linter
+		msg    string
+		want   []commit.Diagnostic
+	}{
+		"valid":             {msg: "fix(repo): something"},
+		"valid body":        {msg: "fix(repo): something\n\nmore info"},
+		"valid no scope":    {msg: "feat: something"},
+		"valid breaking":    {msg: "feat!: something"},
+		"empty":             {msg: "", want: diag(1, "empty commit title")},
+		"no type":           {msg: "123 something", want: diag(1, "the title doesn't start with a type, it will be listed under Misc")},
+		"unknown type":      {msg: "update: something", want: diag(1, `unknown type "update", it will be listed under Misc`)},
+		"missing desc":      {msg: "fix(repo):", want: diag(1, "missing description after the type")},
+		"no blank line":     {msg: "fix: something\nmore info", want: diag(2, "the title should be separated from the body by a blank line")},
+		"allowed verb":      {linter: commit.Linter{Verbs: []string{"fix"}}, msg: "fixed: something"},
+		"not allowed verb":  {linter: commit.Linter{Verbs: []string{"Fix"}}, msg: "feat: something", want: diag(1, `type "Feature" is not allowed`)},
+		"allowed scope":     {linter: commit.Linter{Scopes: []string{"repo", "cli"}}, msg: "fix(repo,cli): something"},
+		"not allowed scope": {linter: commit.Linter{Scopes: []string{"repo"}}, msg: "fix(repo,cli): something", want: diag(1, `scope "cli" is not allowed`)},
+		"multiple": {
+			msg: "fix:\nmore info",
+			want: []commit.Diagnostic{
+				{Line: 1, Message: "missing description after the type"},
+				{Line: 2, Message: "the title should be separated from the body by a blank line"},
+			},
+		},
+	}
+
+	for name, tc := range tcs {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			got := tc.linter.Lint(tc.msg)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("(-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func testLinterCleanMessage(t *testing.T) {
+	t.Parallel()
+	msg := "fix: something\n\nbody\n# Please enter the commit message\n" +
+		"# ------------------------ >8 ------------------------\ndiff --git a/b\n"
+	got := commit.CleanMessage(msg)
+	want := "fix: something\n\nbody"
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("(-want +got):\n%s", diff)
+	}
+}
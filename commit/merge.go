@@ -0,0 +1,77 @@
+package commit
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// MergeStrategy decides how the merge and squash-merge commits are listed.
+type MergeStrategy string
+
+const (
+	// MergeKeep lists all commits as they are, including the merge commits.
+	MergeKeep MergeStrategy = "keep"
+	// MergeSkip leaves out the merge commits.
+	MergeSkip MergeStrategy = "skip"
+	// MergePR lists the title of the pull request of each merge commit in
+	// place of the commits it brought in. The list of the original commits in
+	// the body of squash-merge commits, whose titles end with "(#N)", are also
+	// removed.
+	MergePR MergeStrategy = "pr"
+)
+
+// ParseMergeStrategy returns the MergeStrategy matching the name. An empty
+// name results in MergeKeep.
+func ParseMergeStrategy(name string) (MergeStrategy, error) {
+	switch s := MergeStrategy(strings.ToLower(name)); s {
+	case "":
+		return MergeKeep, nil
+	case MergeKeep, MergeSkip, MergePR:
+		return s, nil
+	}
+	return "", fmt.Errorf("unknown merge strategy %q, valid values are: %s, %s, %s", name, MergeKeep, MergeSkip, MergePR)
+}
+
+// logOptions returns the options for selecting the commits.
+func (m MergeStrategy) logOptions() LogOptions {
+	return LogOptions{
+		NoMerges:    m == MergeSkip,
+		FirstParent: m == MergePR,
+	}
+}
+
+var (
+	mergePRRe = regexp.MustCompile(`^Merge pull request (#\d+) from \S+`)
+	// squashRe matches the titles GitHub gives to the squash-merge commits.
+	squashRe = regexp.MustCompile(`\s\(#\d+\)\s*$`)
+)
+
+// prMessage replaces the title of a GitHub merge commit with the pull request
+// title, and removes the list of the original commits GitHub adds to the body
+// of squash-merge commits. The other commits are returned as they are.
+func prMessage(msg string) string {
+	lines := strings.Split(strings.TrimLeft(msg, "\n"), "\n")
+	m := mergePRRe.FindStringSubmatch(lines[0])
+	if m == nil && !squashRe.MatchString(lines[0]) {
+		return msg
+	}
+	if m != nil {
+		for i, line := range lines[1:] {
+			if strings.TrimSpace(line) == "" {
+				continue
+			}
+			lines = append([]string{fmt.Sprintf("%s (%s)", line, m[1])}, lines[i+2:]...)
+			break
+		}
+	}
+
+	ret := make([]string, 0, len(lines))
+	for i, line := range lines {
+		if i > 0 && strings.HasPrefix(line, "* ") {
+			continue
+		}
+		ret = append(ret, line)
+	}
+	return strings.Join(ret, "\n")
+}
@@ -0,0 +1,28 @@
+package commit_test
+
+import (
+	"testing"
+
+	"github.com/arsham/gitrelease/commit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMergeStrategy(t *testing.T) {
+	t.Parallel()
+	tcs := map[string]commit.MergeStrategy{
+		"":     commit.MergeKeep,
+		"keep": commit.MergeKeep,
+		"skip": commit.MergeSkip,
+		"pr":   commit.MergePR,
+		"PR":   commit.MergePR,
+	}
+	for name, want := range tcs {
+		got, err := commit.ParseMergeStrategy(name)
+		require.NoError(t, err, name)
+		assert.Equal(t, want, got, name)
+	}
+
+	_, err := commit.ParseMergeStrategy("squash")
+	assert.Error(t, err)
+}
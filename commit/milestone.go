@@ -0,0 +1,151 @@
+package commit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Milestone is a milestone of the issues and pull requests on GitHub.
+type Milestone struct {
+	Number  int    `json:"number"`
+	Title   string `json:"title"`
+	State   string `json:"state"`
+	HTMLURL string `json:"html_url"`
+}
+
+// Open returns true if the milestone is not closed.
+func (m Milestone) Open() bool {
+	return m.State != "closed"
+}
+
+// Milestones returns all open and closed milestones of the user's repo.
+func (g Git) Milestones(ctx context.Context, token, user, repo string) ([]Milestone, error) {
+	var milestones []Milestone
+	err := g.getPages(ctx, token, repo, fmt.Sprintf("/repos/%s/%s/milestones?state=all", user, repo), func(r io.Reader) error {
+		var page []Milestone
+		if err := json.NewDecoder(r).Decode(&page); err != nil {
+			return errors.Wrap(err, "decoding milestones")
+		}
+		milestones = append(milestones, page...)
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "listing milestones")
+	}
+	return milestones, nil
+}
+
+// CloseMilestone closes the milestone with the number.
+func (g Git) CloseMilestone(ctx context.Context, token, user, repo string, number int) error {
+	err := g.send(ctx, token, repo, CloseMilestoneCall(user, repo, number))
+	return errors.Wrapf(err, "closing milestone %d", number)
+}
+
+// CloseMilestoneCall returns the API call CloseMilestone makes for closing the
+// milestone.
+func CloseMilestoneCall(user, repo string, number int) APICall {
+	path := fmt.Sprintf("/repos/%s/%s/milestones/%d", user, repo, number)
+	return newCall(http.MethodPatch, path, map[string]string{"state": "closed"})
+}
+
+// MilestoneIssues returns the numbers of the open issues and pull requests in
+// the milestone.
+func (g Git) MilestoneIssues(ctx context.Context, token, user, repo string, number int) ([]int, error) {
+	var ret []int
+	path := fmt.Sprintf("/repos/%s/%s/issues?milestone=%d&state=open", user, repo, number)
+	err := g.getPages(ctx, token, repo, path, func(r io.Reader) error {
+		var page []struct {
+			Number int `json:"number"`
+		}
+		if err := json.NewDecoder(r).Decode(&page); err != nil {
+			return errors.Wrap(err, "decoding issues")
+		}
+		for _, issue := range page {
+			ret = append(ret, issue.Number)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "listing issues of milestone %d", number)
+	}
+	return ret, nil
+}
+
+// SetMilestone moves the issue or pull request to the milestone.
+func (g Git) SetMilestone(ctx context.Context, token, user, repo string, issue, milestone int) error {
+	err := g.send(ctx, token, repo, SetMilestoneCall(user, repo, issue, milestone))
+	return errors.Wrapf(err, "moving #%d to milestone %d", issue, milestone)
+}
+
+// SetMilestoneCall returns the API call SetMilestone makes for moving the
+// issue to the milestone.
+func SetMilestoneCall(user, repo string, issue, milestone int) APICall {
+	path := fmt.Sprintf("/repos/%s/%s/issues/%d", user, repo, issue)
+	return newCall(http.MethodPatch, path, map[string]int{"milestone": milestone})
+}
+
+// MilestoneForTag returns the milestone named after the tag, with or without
+// the "v" prefix. It returns nil if there is none.
+func MilestoneForTag(milestones []Milestone, tag string) *Milestone {
+	name := strings.TrimPrefix(strings.ToLower(tag), "v")
+	for i, m := range milestones {
+		if strings.TrimPrefix(strings.ToLower(m.Title), "v") == name {
+			return &milestones[i]
+		}
+	}
+	return nil
+}
+
+// NextMilestone returns the open milestone with the lowest version after the
+// tag. It returns nil if there is none.
+func NextMilestone(milestones []Milestone, tag string) *Milestone {
+	var next *Milestone
+	for i, m := range milestones {
+		if !m.Open() || compareVersions(m.Title, tag) <= 0 {
+			continue
+		}
+		if next == nil || compareVersions(m.Title, next.Title) < 0 {
+			next = &milestones[i]
+		}
+	}
+	return next
+}
+
+var versionPartRe = regexp.MustCompile(`\d+`)
+
+// compareVersions compares the numbers in the versions from left to right,
+// e.g. "v1.10.0" is after "1.9". It returns -1, 0 or 1 if a is before, equal to
+// or after b.
+func compareVersions(a, b string) int {
+	pa := versionPartRe.FindAllString(a, -1)
+	pb := versionPartRe.FindAllString(b, -1)
+	for i := 0; i < len(pa) || i < len(pb); i++ {
+		var na, nb int
+		if i < len(pa) {
+			na, _ = strconv.Atoi(pa[i]) // nolint:errcheck // they are all digits.
+		}
+		if i < len(pb) {
+			nb, _ = strconv.Atoi(pb[i]) // nolint:errcheck // they are all digits.
+		}
+		switch {
+		case na < nb:
+			return -1
+		case na > nb:
+			return 1
+		}
+	}
+	return 0
+}
+
+// MilestoneLink returns a markdown line linking to the milestone.
+func MilestoneLink(m Milestone) string {
+	return fmt.Sprintf("[Milestone %s](%s)", m.Title, m.HTMLURL)
+}
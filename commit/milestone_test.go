@@ -0,0 +1,146 @@
+package commit_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/arsham/gitrelease/commit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var milestones = []commit.Milestone{
+	{Number: 1, Title: "v0.9.0", State: "closed"},
+	{Number: 2, Title: "1.0.0", State: "open"},
+	{Number: 3, Title: "v1.10.0", State: "open"},
+	{Number: 4, Title: "v1.2.0", State: "open"},
+	{Number: 5, Title: "v1.1.0", State: "closed"},
+	{Number: 6, Title: "Backlog", State: "open"},
+}
+
+func TestMilestoneForTag(t *testing.T) {
+	t.Parallel()
+	tcs := map[string]struct {
+		tag  string
+		want int
+	}{
+		"same":      {tag: "v1.2.0", want: 4},
+		"no prefix": {tag: "1.2.0", want: 4},
+		"prefix":    {tag: "v1.0.0", want: 2},
+		"closed":    {tag: "v0.9.0", want: 1},
+		"missing":   {tag: "v3.0.0"},
+	}
+	for name, tc := range tcs {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			got := commit.MilestoneForTag(milestones, tc.tag)
+			if tc.want == 0 {
+				assert.Nil(t, got)
+				return
+			}
+			require.NotNil(t, got)
+			assert.Equal(t, tc.want, got.Number)
+		})
+	}
+}
+
+func TestNextMilestone(t *testing.T) {
+	t.Parallel()
+	tcs := map[string]struct {
+		tag  string
+		want int
+	}{
+		"next":         {tag: "v1.0.0", want: 4},
+		"skips closed": {tag: "v1.0.5", want: 4},
+		"numeric":      {tag: "v1.2.0", want: 3},
+		"last":         {tag: "v1.10.0"},
+	}
+	for name, tc := range tcs {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			got := commit.NextMilestone(milestones, tc.tag)
+			if tc.want == 0 {
+				assert.Nil(t, got)
+				return
+			}
+			require.NotNil(t, got)
+			assert.Equal(t, tc.want, got.Number)
+		})
+	}
+}
+
+func TestMilestoneAPI(t *testing.T) {
+	t.Parallel()
+	var patches []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/repos/arsham/gitrelease/milestones":
+			assert.Equal(t, "all", r.URL.Query().Get("state"))
+			fmt.Fprint(w, `[{"number": 2, "title": "v0.2.0", "state": "open", "html_url": "https://github.com/m/2"}]`)
+		case r.Method == http.MethodGet && r.URL.Path == "/repos/arsham/gitrelease/issues":
+			assert.Equal(t, "2", r.URL.Query().Get("milestone"))
+			assert.Equal(t, "open", r.URL.Query().Get("state"))
+			fmt.Fprint(w, `[{"number": 12}, {"number": 13}]`)
+		case r.Method == http.MethodPatch:
+			var v map[string]interface{}
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&v))
+			patches = append(patches, fmt.Sprintf("%s %v", r.URL.Path, v))
+			fmt.Fprint(w, `{}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	ctx := context.Background()
+	g := commit.Git{BaseURL: srv.URL}
+	got, err := g.Milestones(ctx, "token", "arsham", "gitrelease")
+	require.NoError(t, err)
+	want := []commit.Milestone{{Number: 2, Title: "v0.2.0", State: "open", HTMLURL: "https://github.com/m/2"}}
+	assert.Equal(t, want, got)
+	assert.Equal(t, "[Milestone v0.2.0](https://github.com/m/2)", commit.MilestoneLink(got[0]))
+
+	issues, err := g.MilestoneIssues(ctx, "token", "arsham", "gitrelease", 2)
+	require.NoError(t, err)
+	assert.Equal(t, []int{12, 13}, issues)
+
+	require.NoError(t, g.SetMilestone(ctx, "token", "arsham", "gitrelease", 12, 3))
+	require.NoError(t, g.CloseMilestone(ctx, "token", "arsham", "gitrelease", 2))
+	wantPatches := []string{
+		"/repos/arsham/gitrelease/issues/12 map[milestone:3]",
+		"/repos/arsham/gitrelease/milestones/2 map[state:closed]",
+	}
+	assert.Equal(t, wantPatches, patches)
+
+	calls := []string{
+		commit.SetMilestoneCall("arsham", "gitrelease", 12, 3).String(),
+		commit.CloseMilestoneCall("arsham", "gitrelease", 2).String(),
+	}
+	wantCalls := []string{
+		`PATCH /repos/arsham/gitrelease/issues/12 {"milestone":3}`,
+		`PATCH /repos/arsham/gitrelease/milestones/2 {"state":"closed"}`,
+	}
+	assert.Equal(t, wantCalls, calls)
+}
+
+func TestMilestoneAPICancel(t *testing.T) {
+	t.Parallel()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request: %s %s", r.Method, r.URL)
+	}))
+	t.Cleanup(srv.Close)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	g := commit.Git{BaseURL: srv.URL}
+	_, err := g.Milestones(ctx, "token", "arsham", "gitrelease")
+	assert.ErrorIs(t, err, context.Canceled)
+	_, err = g.MilestoneIssues(ctx, "token", "arsham", "gitrelease", 2)
+	assert.ErrorIs(t, err, context.Canceled)
+}
@@ -0,0 +1,110 @@
+package commit
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// A Repository reads the tags, commits and remotes of a git repository.
+type Repository interface {
+	// LatestTag returns the closest tag reachable from HEAD.
+	LatestTag(ctx context.Context) (string, error)
+	// PreviousTag returns the closest tag reachable from the parent of the
+	// tag.
+	PreviousTag(ctx context.Context, tag string) (string, error)
+	// Tags returns all tags sorted by their version from the oldest to the
+	// newest.
+	Tags(ctx context.Context) ([]string, error)
+	// Log calls fn with the commits reachable from "to" but not from "from",
+	// from the newest to the oldest. All commits reachable from "to" are
+	// walked if "from" is empty. It stops and returns the error if fn returns
+	// one.
+	Log(ctx context.Context, from, to string, opts LogOptions, fn func(Commit) error) error
+	// TagAnnotation returns the annotation of the tag, or nil if the tag is
+	// not annotated.
+	TagAnnotation(ctx context.Context, tag string) (*Annotation, error)
+	// RemoteURL returns the address of the remote.
+	RemoteURL(ctx context.Context, remote string) (string, error)
+	// GitPath returns the absolute path of the name inside the git directory,
+	// e.g. .git/name. The hooks path respects the core.hooksPath setting.
+	GitPath(ctx context.Context, name string) (string, error)
+}
+
+// LogOptions select the commits and their details returned by Log.
+type LogOptions struct {
+	// NoMerges leaves out the commits with more than one parent.
+	NoMerges bool
+	// FirstParent only follows the first parent of the merge commits.
+	FirstParent bool
+	// Files sets the Files of the entries. The files of the merge commits are
+	// the ones they change compared to their first parent.
+	Files bool
+}
+
+// Commit is a commit returned by Log.
+type Commit struct {
+	Hash string
+	// Parents are the hashes of the parents. The first commit has none, and
+	// the merge commits have more than one.
+	Parents   []string
+	Author    Signature
+	Committer Signature
+	// Message is the whole message, including the trailers.
+	Message string
+	// Trailers are the "Key: value" lines at the end of the message, e.g.
+	// Signed-off-by, with the continuation lines unfolded.
+	Trailers []Trailer
+	// Files are the changed files if LogOptions.Files is set.
+	Files []string
+}
+
+// Signature is the person who authored or committed a commit, and when.
+type Signature struct {
+	Name  string
+	Email string
+	When  time.Time
+}
+
+// String returns the signature in the "Name <email>" form.
+func (s Signature) String() string {
+	return fmt.Sprintf("%s <%s>", s.Name, s.Email)
+}
+
+// Trailer is a "Key: value" line at the end of a commit message.
+type Trailer struct {
+	Key   string
+	Value string
+}
+
+// Backend is the name of a built-in Repository implementation.
+type Backend string
+
+const (
+	// BackendExec runs the git binary. This is the default backend.
+	BackendExec Backend = "exec"
+	// BackendGo reads the repository in pure Go, and doesn't need git to be
+	// installed.
+	BackendGo Backend = "go"
+)
+
+// ParseBackend returns the Backend matching the name. An empty name results
+// in BackendExec.
+func ParseBackend(name string) (Backend, error) {
+	switch b := Backend(strings.ToLower(name)); b {
+	case "":
+		return BackendExec, nil
+	case BackendExec, BackendGo:
+		return b, nil
+	}
+	return "", fmt.Errorf("unknown git backend %q, valid values are: %s, %s", name, BackendExec, BackendGo)
+}
+
+// repository returns the Repository of the backend for the directory.
+func (b Backend) repository(dir string) Repository {
+	if b == BackendGo {
+		return GoGitRepository{Dir: dir}
+	}
+	return ExecRepository{Dir: dir}
+}
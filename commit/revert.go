@@ -0,0 +1,156 @@
+package commit
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	revertRe     = regexp.MustCompile(`^Revert "(.+)"\s*$`)
+	revertBodyRe = regexp.MustCompile(`This reverts commit ([[:xdigit:]]{7,40})`)
+)
+
+// RevertVerb is the section the reverts are listed in when the commits they
+// revert are not in the logs.
+const RevertVerb = "Reverts"
+
+type revertEntry struct {
+	commit Commit
+	title  string
+	// target is the title of the reverted commit.
+	target string
+	// sha is the hash of the reverted commit, which can be abbreviated.
+	sha    string
+	revert bool
+}
+
+// dropReverts removes the reverts and the commits they revert from the
+// commits. The commits should be ordered from the newest to the oldest, as git
+// log returns them. The reverted commits are found by the hash in the body of
+// the revert, or by the title in its "Revert "title"" form. The reverts that
+// don't match any commit are returned separately. A revert of a revert is
+// treated as the original commit.
+func dropReverts(commits []Commit) (kept []Commit, reverts []Group) {
+	active := make([]revertEntry, 0, len(commits))
+	// restored holds the commits that are brought back if the revert with the
+	// hash is reverted.
+	restored := make(map[string]revertEntry)
+	for i := len(commits) - 1; i >= 0; i-- {
+		entry, ok := newRevertEntry(commits[i])
+		if !ok {
+			continue
+		}
+		if entry.revert {
+			if j := findRevertTarget(active, entry); j >= 0 {
+				if entry.commit.Hash != "" {
+					restored[entry.commit.Hash] = active[j]
+				}
+				active = append(active[:j], active[j+1:]...)
+				continue
+			}
+			if target, ok := findRestored(restored, entry.sha); ok {
+				active = append(active, target)
+				continue
+			}
+		}
+		active = append(active, entry)
+	}
+
+	kept = make([]Commit, 0, len(active))
+	for i := len(active) - 1; i >= 0; i-- {
+		entry := active[i]
+		if !entry.revert {
+			kept = append(kept, entry.commit)
+			continue
+		}
+		desc := entry.target
+		if desc == "" {
+			desc = entry.title
+		}
+		reverts = append(reverts, Group{
+			raw:         entry.commit.Message,
+			Verb:        RevertVerb,
+			Description: desc,
+		})
+	}
+	return kept, reverts
+}
+
+func newRevertEntry(c Commit) (revertEntry, bool) {
+	msg := strings.TrimLeft(c.Message, " \n")
+	if strings.TrimSpace(msg) == "" {
+		return revertEntry{}, false
+	}
+	lines := strings.SplitN(msg, "\n", 2)
+	title := strings.TrimSpace(lines[0])
+	var body string
+	if len(lines) > 1 {
+		body = lines[1]
+	}
+
+	// A revert of a revert brings back the original commit.
+	for {
+		m := revertRe.FindStringSubmatch(title)
+		if m == nil {
+			break
+		}
+		inner := revertRe.FindStringSubmatch(m[1])
+		if inner == nil {
+			break
+		}
+		title = inner[1]
+		msg = title
+		body = ""
+	}
+
+	c.Message = msg
+	entry := revertEntry{
+		commit: c,
+		title:  title,
+	}
+	if m := revertRe.FindStringSubmatch(title); m != nil {
+		entry.revert = true
+		entry.target = m[1]
+	}
+	if m := revertBodyRe.FindStringSubmatch(body); m != nil {
+		entry.revert = true
+		entry.sha = strings.ToLower(m[1])
+	}
+	return entry, true
+}
+
+// findRevertTarget returns the index of the commit the revert reverts, or -1
+// if there is none. The commit is looked up by the hash in the body of the
+// revert, and then by the newest commit with the title that is not a revert.
+func findRevertTarget(entries []revertEntry, revert revertEntry) int {
+	if revert.sha != "" {
+		for i := len(entries) - 1; i >= 0; i-- {
+			if strings.HasPrefix(entries[i].commit.Hash, revert.sha) {
+				return i
+			}
+		}
+	}
+	if revert.target == "" {
+		return -1
+	}
+	for i := len(entries) - 1; i >= 0; i-- {
+		if !entries[i].revert && entries[i].title == revert.target {
+			return i
+		}
+	}
+	return -1
+}
+
+// findRestored returns the commit that is brought back by reverting the revert
+// with the hash.
+func findRestored(restored map[string]revertEntry, sha string) (revertEntry, bool) {
+	if sha == "" {
+		return revertEntry{}, false
+	}
+	for hash, entry := range restored {
+		if strings.HasPrefix(hash, sha) {
+			return entry, true
+		}
+	}
+	return revertEntry{}, false
+}
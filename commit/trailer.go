@@ -0,0 +1,104 @@
+package commit
+
+import (
+	"regexp"
+	"strings"
+)
+
+// trailerRe matches a "Key: value" trailer line. The key is made of letters,
+// digits and hyphens, and can be followed by spaces.
+var trailerRe = regexp.MustCompile(`^([[:alnum:]-]+)[ \t]*:[ \t]*(.*)$`)
+
+// ParseTrailers returns the trailers in the last paragraph of the message,
+// following the rules of git interpret-trailers. The title is never a trailer.
+// The paragraph is a trailer block if all of its lines are trailers, or if at
+// least a quarter of them are and one of them is generated by git, e.g.
+// Signed-off-by. The continuation lines starting with a space are unfolded
+// into the value of their trailer, and the other lines are left out.
+func ParseTrailers(msg string) []Trailer {
+	_, trailers := splitTrailers(msg)
+	return trailers
+}
+
+// splitTrailers returns the message without its trailer block, and the
+// trailers in the block. The message is returned as is if it has no trailers.
+func splitTrailers(msg string) (string, []Trailer) {
+	lines := strings.Split(strings.TrimRight(strings.ReplaceAll(msg, "\r\n", "\n"), " \t\n"), "\n")
+	title := 0
+	for title < len(lines) && strings.TrimSpace(lines[title]) != "" {
+		title++
+	}
+	begin := len(lines)
+	for begin > title && strings.TrimSpace(lines[begin-1]) != "" {
+		begin--
+	}
+	if begin <= title {
+		return msg, nil
+	}
+	block := lines[begin:]
+
+	var trailers, others int
+	recognised := false
+	for i, line := range block {
+		switch {
+		case i > 0 && (line[0] == ' ' || line[0] == '\t'):
+			// A continuation line is counted with its trailer.
+		case strings.HasPrefix(line, "(cherry picked from commit "):
+			trailers++
+			recognised = true
+		case trailerRe.MatchString(line):
+			trailers++
+			if strings.HasPrefix(strings.ToLower(line), "signed-off-by") {
+				recognised = true
+			}
+		default:
+			others++
+		}
+	}
+	if trailers == 0 || (others > 0 && (!recognised || trailers*3 < others)) {
+		return msg, nil
+	}
+
+	var ret []Trailer
+	last := -1
+	for i, line := range block {
+		if i > 0 && (line[0] == ' ' || line[0] == '\t') {
+			if last >= 0 {
+				ret[last].Value = strings.TrimSpace(ret[last].Value + " " + strings.TrimSpace(line))
+			}
+			continue
+		}
+		last = -1
+		if m := trailerRe.FindStringSubmatch(line); m != nil {
+			ret = append(ret, Trailer{Key: m[1], Value: strings.TrimSpace(m[2])})
+			last = len(ret) - 1
+		}
+	}
+	return strings.TrimRight(strings.Join(lines[:begin], "\n"), "\n"), ret
+}
+
+// parseTrailerLines parses the output of the %(trailers:only,unfold) format of
+// git log, which is a trailer on each line.
+func parseTrailerLines(s string) []Trailer {
+	var ret []Trailer
+	for _, line := range strings.Split(s, "\n") {
+		if m := trailerRe.FindStringSubmatch(line); m != nil {
+			ret = append(ret, Trailer{Key: m[1], Value: strings.TrimSpace(m[2])})
+		}
+	}
+	return ret
+}
+
+// personRe matches the "Name <email>" values of the trailers like
+// Co-authored-by.
+var personRe = regexp.MustCompile(`^(.+?)\s*<[^<>\s]+@[^<>\s]+>$`)
+
+// String returns the trailer in the "Key: value" form. The email addresses
+// are left out of the "Name <email>" values.
+func (t Trailer) String() string {
+	value := t.Value
+	if m := personRe.FindStringSubmatch(value); m != nil {
+		value = m[1]
+	}
+	return t.Key + ": " + value
+}
@@ -0,0 +1,94 @@
+package commit_test
+
+import (
+	"testing"
+
+	"github.com/arsham/gitrelease/commit"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseTrailers(t *testing.T) {
+	t.Parallel()
+	tcs := map[string]struct {
+		msg  string
+		want []commit.Trailer
+	}{
+		"title only":    {msg: "fix: thing"},
+		"title trailer": {msg: "Refs: #12"},
+		"body only":     {msg: "fix: thing\n\nIt was broken."},
+		"trailers": {
+			msg: "fix: thing\n\nIt was broken.\n\nRefs: #12\nCo-authored-by: John <john@example.com>\n",
+			want: []commit.Trailer{
+				{Key: "Refs", Value: "#12"},
+				{Key: "Co-authored-by", Value: "John <john@example.com>"},
+			},
+		},
+		"no body": {
+			msg:  "fix: thing\n\nReviewed-by: Jane <jane@example.com>",
+			want: []commit.Trailer{{Key: "Reviewed-by", Value: "Jane <jane@example.com>"}},
+		},
+		"not the last paragraph": {
+			msg: "fix: thing\n\nRefs: #12\n\nIt was broken.",
+		},
+		"mixed": {
+			msg: "fix: thing\n\nRefs: #12\nIt was broken.",
+		},
+		"mixed with git trailer": {
+			msg: "fix: thing\n\nIt was broken.\nRefs: #12\nSigned-off-by: arsham <arsham@github.com>",
+			want: []commit.Trailer{
+				{Key: "Refs", Value: "#12"},
+				{Key: "Signed-off-by", Value: "arsham <arsham@github.com>"},
+			},
+		},
+		"too few": {
+			msg: "fix: thing\n\nIt\nwas\nbroken\nbadly.\nSigned-off-by: arsham <arsham@github.com>",
+		},
+		"cherry picked": {
+			msg:  "fix: thing\n\n(cherry picked from commit 1234567)\nSigned-off-by: arsham <arsham@github.com>",
+			want: []commit.Trailer{{Key: "Signed-off-by", Value: "arsham <arsham@github.com>"}},
+		},
+		"continuation": {
+			msg:  "fix: thing\n\nRefs: #12,\n  #13\n\tand #14",
+			want: []commit.Trailer{{Key: "Refs", Value: "#12, #13 and #14"}},
+		},
+		"space before separator": {
+			msg:  "fix: thing\n\nRefs : #12",
+			want: []commit.Trailer{{Key: "Refs", Value: "#12"}},
+		},
+		"windows line endings": {
+			msg:  "fix: thing\r\n\r\nRefs: #12\r\n",
+			want: []commit.Trailer{{Key: "Refs", Value: "#12"}},
+		},
+	}
+	for name, tc := range tcs {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			got := commit.ParseTrailers(tc.msg)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("(-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestTrailerString(t *testing.T) {
+	t.Parallel()
+	tcs := map[string]struct {
+		trailer commit.Trailer
+		want    string
+	}{
+		"value":  {trailer: commit.Trailer{Key: "Refs", Value: "#12"}, want: "Refs: #12"},
+		"person": {trailer: commit.Trailer{Key: "Co-authored-by", Value: "John Doe <john@example.com>"}, want: "Co-authored-by: John Doe"},
+		"angle":  {trailer: commit.Trailer{Key: "Link", Value: "<https://example.com>"}, want: "Link: <https://example.com>"},
+	}
+	for name, tc := range tcs {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			if got := tc.trailer.String(); got != tc.want {
+				t.Errorf("got %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,215 @@
+package commit
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptrace"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Transport is an http.RoundTripper for the API calls. It retries the requests
+// that fail with network errors, server errors or rate limits, and logs the
+// requests if Log is set. The requests that are not idempotent, e.g. POST, are
+// only retried on rate limits, or when they fail before they are sent, so
+// they are never made twice.
+type Transport struct {
+	// Base makes the requests. The http.DefaultTransport is used if nil.
+	Base http.RoundTripper
+	// Log receives a line for each request, response and retry. The
+	// authorization headers are redacted.
+	Log io.Writer
+	// Timeout limits each attempt. There is no limit if zero.
+	Timeout time.Duration
+	// Retries is the number of attempts after the first one fails.
+	Retries int
+	// Backoff is the delay before the first retry, which is doubled after
+	// each attempt. It is only used when the response doesn't say how long to
+	// wait. Defaults to a second.
+	Backoff time.Duration
+	// MaxWait is the longest the Transport waits before retrying. The
+	// response is returned as is if it asks for a longer wait, e.g. when the
+	// rate limit resets in an hour. There is no limit if zero.
+	MaxWait time.Duration
+
+	mu sync.Mutex
+}
+
+// RoundTrip makes the request, and retries it if it fails with a network
+// error, a 5xx status, or a rate limit.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	idempotent := idempotentMethods[req.Method]
+	backoff := t.Backoff
+	if backoff <= 0 {
+		backoff = time.Second
+	}
+	// The body of the request can only be sent again if it can be rewound.
+	replayable := req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+	attempt := req
+	for i := 0; ; i++ {
+		resp, sent, err := t.roundTrip(attempt)
+		wait, retry := t.retryWait(req.Context(), resp, err, backoff, idempotent || !sent)
+		if !retry || i >= t.Retries || !replayable {
+			return resp, err
+		}
+		if t.MaxWait > 0 && wait > t.MaxWait {
+			t.logf("not retrying %s %s, as it has to wait %s\n", req.Method, req.URL.Redacted(), wait.Round(time.Second))
+			return resp, err
+		}
+		if resp != nil {
+			// Reading the rest of the body lets the connection be reused.
+			// nolint:errcheck // the response is discarded.
+			io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+			// nolint:errcheck // the response is discarded.
+			resp.Body.Close()
+		}
+		t.logf("retrying %s %s in %s (%d of %d)\n", req.Method, req.URL.Redacted(), wait, i+1, t.Retries)
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+		backoff *= 2
+
+		attempt = req.Clone(req.Context())
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			attempt.Body = body
+		}
+	}
+}
+
+// roundTrip makes a single attempt. It returns true if any of the request is
+// written to the connection.
+func (t *Transport) roundTrip(req *http.Request) (*http.Response, bool, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	t.logRequest(req)
+	start := time.Now()
+
+	ctx := req.Context()
+	cancel := context.CancelFunc(func() {})
+	if t.Timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, t.Timeout)
+	}
+	// The request is written in another goroutine, which can still be running
+	// when RoundTrip returns an error.
+	var wrote int32
+	ctx = httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+		WroteHeaderField: func(string, []string) { atomic.StoreInt32(&wrote, 1) },
+		WroteRequest:     func(httptrace.WroteRequestInfo) { atomic.StoreInt32(&wrote, 1) },
+	})
+	req = req.WithContext(ctx)
+
+	resp, err := base.RoundTrip(req)
+	took := time.Since(start).Round(time.Millisecond)
+	if err != nil {
+		cancel()
+		t.logf("<-- %s %s: %v (%s)\n", req.Method, req.URL.Redacted(), err, took)
+		return nil, atomic.LoadInt32(&wrote) == 1, err
+	}
+	// The timeout covers reading the body, therefore it is cancelled when the
+	// body is closed.
+	resp.Body = &cancelBody{ReadCloser: resp.Body, cancel: cancel}
+	t.logf("<-- %s %s %s (%s)\n", resp.Status, req.Method, req.URL.Redacted(), took)
+	return resp, true, nil
+}
+
+// idempotentMethods are the methods that can be retried after the server
+// received them, as making them again has the same effect.
+var idempotentMethods = map[string]bool{
+	http.MethodGet:     true,
+	http.MethodHead:    true,
+	http.MethodOptions: true,
+	http.MethodTrace:   true,
+	http.MethodPut:     true,
+	http.MethodDelete:  true,
+}
+
+// retryWait returns true if the attempt can be retried, and how long to wait
+// before retrying. The rate limited requests are always retried, as they are
+// rejected before they are handled. The other failures are only retried if
+// safe is true, which means the request is idempotent or it was not sent.
+func (t *Transport) retryWait(ctx context.Context, resp *http.Response, err error, backoff time.Duration, safe bool) (time.Duration, bool) {
+	if err != nil {
+		// The attempt can time out, but the caller can't.
+		return backoff, safe && ctx.Err() == nil
+	}
+	if wait, limited := retryAfter(resp); limited {
+		return wait, true
+	}
+	if resp.StatusCode < 500 || !safe {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+		return time.Duration(secs) * time.Second, true
+	}
+	return backoff, true
+}
+
+func (t *Transport) logRequest(req *http.Request) {
+	if t.Log == nil {
+		return
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "--> %s %s\n", req.Method, req.URL.Redacted())
+	keys := make([]string, 0, len(req.Header))
+	for k := range req.Header {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		for _, v := range req.Header[k] {
+			if k == "Authorization" || k == "Proxy-Authorization" {
+				v = redact(v)
+			}
+			fmt.Fprintf(&b, "    %s: %s\n", k, v)
+		}
+	}
+	t.logf("%s", b.String())
+}
+
+// logf writes to the Log in one go, so the lines of the concurrent requests
+// don't interleave.
+func (t *Transport) logf(format string, a ...interface{}) {
+	if t.Log == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	// nolint:errcheck // logging is best effort.
+	fmt.Fprintf(t.Log, format, a...)
+}
+
+// redact hides the credentials of the authorization header, but keeps its
+// scheme.
+func redact(auth string) string {
+	if i := strings.IndexByte(auth, ' '); i > 0 {
+		return auth[:i] + " [REDACTED]"
+	}
+	return "[REDACTED]"
+}
+
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelBody) Close() error {
+	defer b.cancel()
+	return b.ReadCloser.Close()
+}
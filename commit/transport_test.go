@@ -0,0 +1,393 @@
+package commit_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/arsham/gitrelease/commit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransport(t *testing.T) {
+	t.Parallel()
+	t.Run("Statuses", testTransportStatuses)
+	t.Run("Body", testTransportBody)
+	t.Run("Timeout", testTransportTimeout)
+	t.Run("NetworkError", testTransportNetworkError)
+	t.Run("NotIdempotent", testTransportNotIdempotent)
+	t.Run("RetryAfter", testTransportRetryAfter)
+	t.Run("Cancelled", testTransportCancelled)
+	t.Run("Log", testTransportLog)
+	t.Run("Release", testTransportRelease)
+}
+
+// respond sets the headers and writes the status.
+type respond struct {
+	status  int
+	headers map[string]string
+}
+
+func testTransportStatuses(t *testing.T) {
+	t.Parallel()
+	past := strconv.FormatInt(time.Now().Add(-time.Minute).Unix(), 10)
+	future := strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10)
+	tcs := map[string]struct {
+		responses []respond
+		want      int
+		calls     int32
+	}{
+		"ok": {
+			responses: []respond{{status: http.StatusOK}},
+			want:      http.StatusOK,
+			calls:     1,
+		},
+		"server errors": {
+			responses: []respond{{status: http.StatusInternalServerError}, {status: http.StatusBadGateway}, {status: http.StatusOK}},
+			want:      http.StatusOK,
+			calls:     3,
+		},
+		"gives up": {
+			responses: []respond{{status: http.StatusServiceUnavailable}},
+			want:      http.StatusServiceUnavailable,
+			calls:     3,
+		},
+		"not found": {
+			responses: []respond{{status: http.StatusNotFound}, {status: http.StatusOK}},
+			want:      http.StatusNotFound,
+			calls:     1,
+		},
+		"unprocessable": {
+			responses: []respond{{status: http.StatusUnprocessableEntity}, {status: http.StatusOK}},
+			want:      http.StatusUnprocessableEntity,
+			calls:     1,
+		},
+		"too many requests": {
+			responses: []respond{{status: http.StatusTooManyRequests, headers: map[string]string{"Retry-After": "0"}}, {status: http.StatusOK}},
+			want:      http.StatusOK,
+			calls:     2,
+		},
+		"secondary rate limit": {
+			responses: []respond{{status: http.StatusForbidden, headers: map[string]string{"Retry-After": "0"}}, {status: http.StatusOK}},
+			want:      http.StatusOK,
+			calls:     2,
+		},
+		"rate limit reset": {
+			responses: []respond{{status: http.StatusForbidden, headers: map[string]string{"X-RateLimit-Remaining": "0", "X-RateLimit-Reset": past}}, {status: http.StatusOK}},
+			want:      http.StatusOK,
+			calls:     2,
+		},
+		"rate limit resets later": {
+			responses: []respond{{status: http.StatusForbidden, headers: map[string]string{"X-RateLimit-Remaining": "0", "X-RateLimit-Reset": future}}, {status: http.StatusOK}},
+			want:      http.StatusForbidden,
+			calls:     1,
+		},
+		"forbidden": {
+			responses: []respond{{status: http.StatusForbidden, headers: map[string]string{"X-RateLimit-Remaining": "10"}}, {status: http.StatusOK}},
+			want:      http.StatusForbidden,
+			calls:     1,
+		},
+	}
+	for name, tc := range tcs {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			var calls int32
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				i := int(atomic.AddInt32(&calls, 1)) - 1
+				if i >= len(tc.responses) {
+					i = len(tc.responses) - 1
+				}
+				for k, v := range tc.responses[i].headers {
+					w.Header().Set(k, v)
+				}
+				w.WriteHeader(tc.responses[i].status)
+			}))
+			t.Cleanup(srv.Close)
+
+			client := &http.Client{Transport: &commit.Transport{
+				Retries: 2,
+				Backoff: time.Millisecond,
+				MaxWait: time.Minute,
+			}}
+			resp, err := client.Get(srv.URL)
+			require.NoError(t, err)
+			require.NoError(t, resp.Body.Close())
+			assert.Equal(t, tc.want, resp.StatusCode)
+			assert.Equal(t, tc.calls, atomic.LoadInt32(&calls))
+		})
+	}
+}
+
+func testTransportBody(t *testing.T) {
+	t.Parallel()
+	var (
+		mu     sync.Mutex
+		bodies []string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+		mu.Lock()
+		bodies = append(bodies, string(b))
+		n := len(bodies)
+		mu.Unlock()
+		if n == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	t.Cleanup(srv.Close)
+
+	client := &http.Client{Transport: &commit.Transport{Retries: 1, Backoff: time.Millisecond}}
+	resp, err := client.Post(srv.URL, "application/json", bytes.NewReader([]byte(`{"tag_name":"v1.0.0"}`)))
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	assert.Equal(t, []string{`{"tag_name":"v1.0.0"}`, `{"tag_name":"v1.0.0"}`}, bodies)
+}
+
+func testTransportTimeout(t *testing.T) {
+	t.Parallel()
+	var calls int32
+	stuck := make(chan struct{})
+	defer close(stuck)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			select {
+			case <-stuck:
+			case <-r.Context().Done():
+			}
+			return
+		}
+		fmt.Fprint(w, "ok")
+	}))
+	t.Cleanup(srv.Close)
+
+	client := &http.Client{Transport: &commit.Transport{
+		Timeout: 50 * time.Millisecond,
+		Retries: 1,
+		Backoff: time.Millisecond,
+	}}
+	resp, err := client.Get(srv.URL)
+	require.NoError(t, err)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	assert.Equal(t, "ok", string(body))
+	assert.EqualValues(t, 2, atomic.LoadInt32(&calls))
+}
+
+func testTransportNetworkError(t *testing.T) {
+	t.Parallel()
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			conn, _, err := w.(http.Hijacker).Hijack()
+			assert.NoError(t, err)
+			assert.NoError(t, conn.Close())
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(srv.Close)
+
+	client := &http.Client{Transport: &commit.Transport{Retries: 1, Backoff: time.Millisecond}}
+	resp, err := client.Get(srv.URL)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.EqualValues(t, 2, atomic.LoadInt32(&calls))
+}
+
+func testTransportNotIdempotent(t *testing.T) {
+	t.Parallel()
+	tcs := map[string]struct {
+		method string
+		// hangUp closes the connection of the first request without a
+		// response.
+		hangUp bool
+		// dialErr fails the first connection before the request is sent.
+		dialErr bool
+		want    int
+		calls   int32
+	}{
+		"post server error": {
+			method: http.MethodPost,
+			want:   http.StatusBadGateway,
+			calls:  1,
+		},
+		"patch server error": {
+			method: http.MethodPatch,
+			want:   http.StatusBadGateway,
+			calls:  1,
+		},
+		"put server error": {
+			method: http.MethodPut,
+			want:   http.StatusOK,
+			calls:  2,
+		},
+		"post network error": {
+			method: http.MethodPost,
+			hangUp: true,
+			calls:  1,
+		},
+		"post not sent": {
+			method:  http.MethodPost,
+			dialErr: true,
+			want:    http.StatusOK,
+			calls:   1,
+		},
+	}
+	for name, tc := range tcs {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			var calls int32
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if atomic.AddInt32(&calls, 1) > 1 || tc.dialErr {
+					w.WriteHeader(http.StatusOK)
+					return
+				}
+				if tc.hangUp {
+					conn, _, err := w.(http.Hijacker).Hijack()
+					assert.NoError(t, err)
+					assert.NoError(t, conn.Close())
+					return
+				}
+				w.WriteHeader(http.StatusBadGateway)
+			}))
+			t.Cleanup(srv.Close)
+
+			var dials int32
+			base := &http.Transport{
+				DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+					if tc.dialErr && atomic.AddInt32(&dials, 1) == 1 {
+						return nil, errors.New("connection refused")
+					}
+					var d net.Dialer
+					return d.DialContext(ctx, network, addr)
+				},
+			}
+			t.Cleanup(base.CloseIdleConnections)
+			client := &http.Client{Transport: &commit.Transport{Base: base, Retries: 2, Backoff: time.Millisecond}}
+			req, err := http.NewRequest(tc.method, srv.URL, strings.NewReader(`{}`))
+			require.NoError(t, err)
+			resp, err := client.Do(req)
+			if tc.want == 0 {
+				assert.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				require.NoError(t, resp.Body.Close())
+				assert.Equal(t, tc.want, resp.StatusCode)
+			}
+			assert.Equal(t, tc.calls, atomic.LoadInt32(&calls))
+		})
+	}
+}
+
+func testTransportRetryAfter(t *testing.T) {
+	t.Parallel()
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(srv.Close)
+
+	client := &http.Client{Transport: &commit.Transport{Retries: 1, Backoff: time.Millisecond}}
+	start := time.Now()
+	resp, err := client.Get(srv.URL)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.GreaterOrEqual(t, time.Since(start), time.Second)
+}
+
+func testTransportCancelled(t *testing.T) {
+	t.Parallel()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	t.Cleanup(srv.Close)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+	require.NoError(t, err)
+	client := &http.Client{Transport: &commit.Transport{Retries: 5, Backoff: time.Minute}}
+	_, err = client.Do(req)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func testTransportLog(t *testing.T) {
+	t.Parallel()
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(srv.Close)
+
+	buf := &bytes.Buffer{}
+	client := &http.Client{Transport: &commit.Transport{Retries: 1, Backoff: time.Millisecond, Log: buf}}
+	req, err := http.NewRequest(http.MethodGet, srv.URL+"/rate_limit", nil)
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer ghp_secret")
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+
+	got := buf.String()
+	assert.NotContains(t, got, "ghp_secret")
+	assert.Contains(t, got, "--> GET "+srv.URL+"/rate_limit\n")
+	assert.Contains(t, got, "    Authorization: Bearer [REDACTED]\n")
+	assert.Contains(t, got, "<-- 502 Bad Gateway GET "+srv.URL+"/rate_limit")
+	assert.Contains(t, got, "retrying GET "+srv.URL+"/rate_limit in 1ms (1 of 1)\n")
+	assert.Contains(t, got, "<-- 200 OK GET "+srv.URL+"/rate_limit")
+	assert.Equal(t, 2, strings.Count(got, "Authorization"))
+}
+
+func testTransportRelease(t *testing.T) {
+	t.Parallel()
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, `{}`)
+	}))
+	t.Cleanup(srv.Close)
+
+	g := commit.Git{
+		BaseURL: srv.URL,
+		Client:  &http.Client{Transport: &commit.Transport{Retries: 1, Backoff: time.Millisecond}},
+	}
+	err := g.Release(context.Background(), "token", "arsham", "gitrelease", "v0.1.0", "desc")
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, atomic.LoadInt32(&calls))
+}
@@ -0,0 +1,122 @@
+package commit
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ErrTagNotSigned is returned by VerifyTag when the tag has no signature.
+var ErrTagNotSigned = errors.New("tag is not signed")
+
+// Signer is who signed a tag.
+type Signer struct {
+	// Identity is the name and email of the GPG key, or the principal of the
+	// SSH key in the allowed signers file.
+	Identity string
+	// Key is the fingerprint of the key.
+	Key string
+}
+
+func (s Signer) String() string {
+	return fmt.Sprintf("%s with the key %s", s.Identity, s.Key)
+}
+
+// TrustLevel is the trust of a GPG key, as in git's gpg.minTrustLevel setting.
+type TrustLevel string
+
+// The trust levels, from the lowest to the highest.
+const (
+	TrustUndefined TrustLevel = "undefined"
+	TrustNever     TrustLevel = "never"
+	TrustMarginal  TrustLevel = "marginal"
+	TrustFully     TrustLevel = "fully"
+	TrustUltimate  TrustLevel = "ultimate"
+)
+
+var trustLevels = []TrustLevel{TrustUndefined, TrustNever, TrustMarginal, TrustFully, TrustUltimate}
+
+// ParseTrustLevel returns the TrustLevel matching the name. An empty name
+// results in TrustFully.
+func ParseTrustLevel(name string) (TrustLevel, error) {
+	if name == "" {
+		return TrustFully, nil
+	}
+	for _, l := range trustLevels {
+		if strings.EqualFold(name, string(l)) {
+			return l, nil
+		}
+	}
+	return "", fmt.Errorf("unknown trust level %q, valid values are: %s, %s, %s, %s, %s", name,
+		TrustUndefined, TrustNever, TrustMarginal, TrustFully, TrustUltimate)
+}
+
+// rank returns the position of the level from the lowest, or -1 if it is not
+// known.
+func (l TrustLevel) rank() int {
+	for i, level := range trustLevels {
+		if level == l {
+			return i
+		}
+	}
+	return -1
+}
+
+var (
+	sshSignerRe = regexp.MustCompile(`Good "git" signature for (.+?) with \S+ key (\S+)`)
+	gpgGoodRe   = regexp.MustCompile(`(?m)^\[GNUPG:\] GOODSIG \S+ (.+)$`)
+	gpgValidRe  = regexp.MustCompile(`(?m)^\[GNUPG:\] VALIDSIG (\S+)`)
+	gpgTrustRe  = regexp.MustCompile(`(?m)^\[GNUPG:\] TRUST_([A-Z]+)`)
+)
+
+// VerifyTag checks the GPG or SSH signature of the tag with the git binary,
+// regardless of the Backend. The SSH signatures are checked against the
+// allowedSigners file if it is not empty, otherwise git's
+// gpg.ssh.allowedSignersFile setting is used. The GPG keys need at least the
+// minTrust level, which defaults to TrustFully. It returns ErrTagNotSigned if
+// the tag has no signature.
+func (g Git) VerifyTag(ctx context.Context, tag, allowedSigners string, minTrust TrustLevel) (*Signer, error) {
+	if minTrust == "" {
+		minTrust = TrustFully
+	}
+	var args []string
+	if allowedSigners != "" {
+		args = append(args, "-c", "gpg.ssh.allowedSignersFile="+allowedSigners)
+	}
+	args = append(args, "verify-tag", "--raw", tag)
+	// nolint:gosec // we need these variables.
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = g.Dir
+	out, err := cmd.CombinedOutput()
+	output := strings.TrimSpace(string(out))
+	if err != nil {
+		// Lightweight tags can't have signatures.
+		if strings.Contains(output, "no signature found") || strings.Contains(output, "non-tag object") {
+			return nil, errors.Wrap(ErrTagNotSigned, tag)
+		}
+		return nil, fmt.Errorf("invalid signature of %s: %s", tag, output)
+	}
+
+	if m := sshSignerRe.FindStringSubmatch(output); m != nil {
+		return &Signer{Identity: m[1], Key: m[2]}, nil
+	}
+	good := gpgGoodRe.FindStringSubmatch(output)
+	valid := gpgValidRe.FindStringSubmatch(output)
+	if good == nil || valid == nil {
+		return nil, fmt.Errorf("could not find the signer of %s: %s", tag, output)
+	}
+	// git accepts the good signatures of any key, even the ones that are not
+	// trusted.
+	trust := TrustUndefined
+	if m := gpgTrustRe.FindStringSubmatch(output); m != nil {
+		trust = TrustLevel(strings.ToLower(m[1]))
+	}
+	if trust.rank() < minTrust.rank() {
+		return nil, fmt.Errorf("the key %s of %s has the %s trust level, but %s is required", valid[1], tag, trust, minTrust)
+	}
+	return &Signer{Identity: good[1], Key: valid[1]}, nil
+}
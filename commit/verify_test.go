@@ -0,0 +1,143 @@
+package commit_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"testing"
+
+	"github.com/arsham/gitrelease/commit"
+	"github.com/blokur/testament"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGitVerifyTag(t *testing.T) {
+	t.Parallel()
+	if _, err := exec.LookPath("ssh-keygen"); err != nil {
+		t.Skip("ssh-keygen is not installed")
+	}
+	ctx := context.Background()
+	dir := createGitRepo(t)
+	keys := t.TempDir()
+	key := path.Join(keys, "key")
+	cmd := exec.CommandContext(ctx, "ssh-keygen", "-q", "-t", "ed25519", "-N", "", "-C", "test", "-f", key)
+	out, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(out))
+	pub, err := os.ReadFile(key + ".pub")
+	require.NoError(t, err)
+
+	allowed := path.Join(keys, "allowed_signers")
+	require.NoError(t, os.WriteFile(allowed, []byte("arsham@github.com "+string(pub)), 0o600))
+	other := path.Join(keys, "other_signers")
+	otherKey := "arsham@github.com ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIJBlvu3Nh6Vm4Z+1hFNnJJ/+XqMW8xeI8Aet0yJ2zn1C\n"
+	require.NoError(t, os.WriteFile(other, []byte(otherKey), 0o600))
+
+	createFile(t, dir, "file.txt", testament.RandomString(20))
+	commitChanges(t, dir, "msg1")
+	createGitTag(t, dir, "v0.0.1")
+	runGit(t, dir, "-c", "gpg.format=ssh", "-c", "user.signingkey="+key, "tag", "-s", "v0.0.2", "-m", "signed")
+
+	g := commit.Git{Dir: dir}
+	signer, err := g.VerifyTag(ctx, "v0.0.2", allowed, "")
+	require.NoError(t, err)
+	assert.Equal(t, "arsham@github.com", signer.Identity)
+	assert.Regexp(t, `^SHA256:`, signer.Key)
+	assert.Equal(t, fmt.Sprintf("arsham@github.com with the key %s", signer.Key), signer.String())
+
+	_, err = g.VerifyTag(ctx, "v0.0.1", allowed, "")
+	assert.ErrorIs(t, err, commit.ErrTagNotSigned)
+
+	_, err = g.VerifyTag(ctx, "v0.0.2", other, "")
+	require.Error(t, err)
+	assert.NotErrorIs(t, err, commit.ErrTagNotSigned)
+	assert.Contains(t, err.Error(), "invalid signature of v0.0.2")
+}
+
+func TestGitVerifyTagGPG(t *testing.T) {
+	t.Parallel()
+	if _, err := exec.LookPath("gpg"); err != nil {
+		t.Skip("gpg is not installed")
+	}
+	ctx := context.Background()
+	dir := createGitRepo(t)
+	keys := t.TempDir()
+
+	gpg := func(home string, stdin []byte, args ...string) []byte {
+		t.Helper()
+		cmd := exec.CommandContext(ctx, "gpg", append([]string{"--homedir", home, "--batch"}, args...)...)
+		cmd.Stdin = bytes.NewReader(stdin)
+		out, err := cmd.Output()
+		require.NoError(t, err)
+		return out
+	}
+	program := func(name string) string {
+		t.Helper()
+		home := path.Join(keys, name)
+		require.NoError(t, os.Mkdir(home, 0o700))
+		t.Cleanup(func() {
+			// nolint:errcheck // the agent may not be running.
+			exec.Command("gpgconf", "--homedir", home, "--kill", "gpg-agent").Run()
+		})
+		script := path.Join(keys, name+".sh")
+		content := fmt.Sprintf("#!/bin/sh\nexec gpg --homedir %s \"$@\"\n", home)
+		require.NoError(t, os.WriteFile(script, []byte(content), 0o700))
+		return script
+	}
+	// The key is ultimately trusted in the keyring it is created in, and its
+	// trust is undefined in the other one.
+	owner := program("owner")
+	other := program("other")
+	gpg(path.Join(keys, "owner"), nil, "--passphrase", "", "--quick-gen-key", "arsham <arsham@github.com>", "ed25519", "sign", "never")
+	pub := gpg(path.Join(keys, "owner"), nil, "--export", "arsham@github.com")
+	gpg(path.Join(keys, "other"), pub, "--import")
+
+	createFile(t, dir, "file.txt", testament.RandomString(20))
+	commitChanges(t, dir, "msg1")
+	runGit(t, dir, "-c", "gpg.program="+owner, "-c", "user.signingkey=arsham@github.com", "tag", "-s", "v0.0.1", "-m", "signed")
+
+	g := commit.Git{Dir: dir}
+	runGit(t, dir, "config", "gpg.program", owner)
+	signer, err := g.VerifyTag(ctx, "v0.0.1", "", "")
+	require.NoError(t, err)
+	assert.Equal(t, "arsham <arsham@github.com>", signer.Identity)
+
+	runGit(t, dir, "config", "gpg.program", other)
+	_, err = g.VerifyTag(ctx, "v0.0.1", "", "")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "has the undefined trust level, but fully is required")
+
+	signer, err = g.VerifyTag(ctx, "v0.0.1", "", commit.TrustUndefined)
+	require.NoError(t, err)
+	assert.Equal(t, "arsham <arsham@github.com>", signer.Identity)
+}
+
+func TestParseTrustLevel(t *testing.T) {
+	t.Parallel()
+	tcs := map[string]struct {
+		name    string
+		want    commit.TrustLevel
+		wantErr bool
+	}{
+		"default":   {name: "", want: commit.TrustFully},
+		"marginal":  {name: "marginal", want: commit.TrustMarginal},
+		"uppercase": {name: "ULTIMATE", want: commit.TrustUltimate},
+		"unknown":   {name: "full", wantErr: true},
+	}
+	for name, tc := range tcs {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			got, err := commit.ParseTrustLevel(tc.name)
+			if tc.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
@@ -0,0 +1,222 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"regexp"
+	"time"
+
+	"github.com/arsham/gitrelease/commit"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+// cfgFile is the path to the configuration file. If empty, a .gitrelease file
+// with any of the supported extensions (e.g. .gitrelease.yaml) is looked up in
+// the current directory.
+var cfgFile string
+
+func initConfig() {
+	viper.AutomaticEnv()
+	if cfgFile != "" {
+		viper.SetConfigFile(cfgFile)
+	} else {
+		viper.SetConfigName(".gitrelease")
+		viper.AddConfigPath(".")
+	}
+
+	err := viper.ReadInConfig()
+	if _, ok := err.(viper.ConfigFileNotFoundError); ok && cfgFile == "" {
+		return
+	}
+	cobra.CheckErr(err)
+}
+
+// newGit returns a Git instance configured with the flags and the
+// configuration file.
+func newGit() (*commit.Git, error) {
+	strategy, err := commit.ParseMergeStrategy(viper.GetString("merge_strategy"))
+	if err != nil {
+		return nil, err
+	}
+	backend, err := commit.ParseBackend(viper.GetString("git.backend"))
+	if err != nil {
+		return nil, err
+	}
+	include, err := includeFlags.rules("filters.include")
+	if err != nil {
+		return nil, err
+	}
+	exclude, err := excludeFlags.rules("filters.exclude")
+	if err != nil {
+		return nil, err
+	}
+	convention, err := commit.ConventionByName(viper.GetString("convention"))
+	if err != nil {
+		return nil, err
+	}
+	return &commit.Git{
+		Remote:        remote,
+		Client:        apiClient(),
+		Backend:       backend,
+		MergeStrategy: strategy,
+		Filter: commit.Filter{
+			Include:    include,
+			Exclude:    exclude,
+			Convention: convention,
+		},
+	}, nil
+}
+
+// apiClient returns the client for the API calls, which retries the failed
+// requests as set in the http section of the configuration file.
+func apiClient() *http.Client {
+	t := &commit.Transport{
+		Timeout: 30 * time.Second,
+		Retries: 3,
+		Backoff: time.Second,
+		MaxWait: time.Minute,
+	}
+	if viper.IsSet("http.timeout") {
+		t.Timeout = viper.GetDuration("http.timeout")
+	}
+	if viper.IsSet("http.retries") {
+		t.Retries = viper.GetInt("http.retries")
+	}
+	if viper.IsSet("http.backoff") {
+		t.Backoff = viper.GetDuration("http.backoff")
+	}
+	if viper.IsSet("http.max_wait") {
+		t.MaxWait = viper.GetDuration("http.max_wait")
+	}
+	if viper.GetBool("verbose") {
+		t.Log = os.Stderr
+	}
+	return &http.Client{Transport: t}
+}
+
+// ruleFlags holds the values of the repeatable flags for filtering commits.
+// They are added to the values in the configuration file.
+type ruleFlags struct {
+	messages []string
+	authors  []string
+	types    []string
+	scopes   []string
+	paths    []string
+}
+
+var includeFlags, excludeFlags ruleFlags
+
+// register adds the flags prefixed with the kind, which is either include or
+// exclude.
+func (r *ruleFlags) register(flags *pflag.FlagSet, kind string) {
+	flags.StringArrayVar(&r.messages, kind+"-message", nil, kind+" commits with messages matching the regexp")
+	flags.StringArrayVar(&r.authors, kind+"-author", nil, kind+` commits with authors matching the regexp, as "Name <email>"`)
+	flags.StringArrayVar(&r.types, kind+"-type", nil, kind+" commits of the type, e.g. feat or Feature")
+	flags.StringArrayVar(&r.scopes, kind+"-scope", nil, kind+" commits with the scope")
+	flags.StringArrayVar(&r.paths, kind+"-path", nil, kind+" commits touching files matching the glob, or inside the directory ending with /")
+}
+
+// rules returns the rules in the configuration file under the key, combined
+// with the flag values.
+func (r *ruleFlags) rules(key string) (commit.Rules, error) {
+	messages, err := compileAll(append(viper.GetStringSlice(key+".messages"), r.messages...))
+	if err != nil {
+		return commit.Rules{}, errors.Wrapf(err, "%s.messages", key)
+	}
+	authors, err := compileAll(append(viper.GetStringSlice(key+".authors"), r.authors...))
+	if err != nil {
+		return commit.Rules{}, errors.Wrapf(err, "%s.authors", key)
+	}
+	return commit.Rules{
+		Messages: messages,
+		Authors:  authors,
+		Types:    append(viper.GetStringSlice(key+".types"), r.types...),
+		Scopes:   append(viper.GetStringSlice(key+".scopes"), r.scopes...),
+		Paths:    append(viper.GetStringSlice(key+".paths"), r.paths...),
+	}, nil
+}
+
+func compileAll(exprs []string) ([]*regexp.Regexp, error) {
+	ret := make([]*regexp.Regexp, 0, len(exprs))
+	for _, expr := range exprs {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regular expression %q: %w", expr, err)
+		}
+		ret = append(ret, re)
+	}
+	return ret, nil
+}
+
+// notesOptions returns the options for rendering the release notes from the
+// flags and the configuration file.
+func notesOptions() ([]commit.Option, error) {
+	convention, err := commit.ConventionByName(viper.GetString("convention"))
+	if err != nil {
+		return nil, err
+	}
+	opts := []commit.Option{commit.WithConvention(convention)}
+	if patterns := viper.GetStringSlice("issues.patterns"); len(patterns) > 0 {
+		res, err := compileAll(patterns)
+		if err != nil {
+			return nil, errors.Wrap(err, "issues.patterns")
+		}
+		opts = append(opts, commit.WithIssueTracker(commit.IssueTracker{
+			Patterns: res,
+			URL:      viper.GetString("issues.url"),
+			Summary:  viper.GetBool("issues.summary"),
+		}))
+	}
+	if aliases := viper.GetStringMapString("scopes.aliases"); len(aliases) > 0 {
+		opts = append(opts, commit.WithScopeAliases(aliases))
+	}
+	if viper.IsSet("trailers") {
+		opts = append(opts, commit.WithTrailers(viper.GetStringSlice("trailers")...))
+	}
+	if viper.GetBool("scopes.sections") {
+		opts = append(opts, commit.WithScopeSections())
+	}
+	return opts, nil
+}
+
+// introduce adds the message of the annotated tag above the notes if it is
+// enabled.
+func introduce(ctx context.Context, g *commit.Git, tag, desc string) (string, error) {
+	if !viper.GetBool("tag_message.enabled") {
+		return desc, nil
+	}
+	a, err := g.TagAnnotation(ctx, tag)
+	if err != nil {
+		return "", errors.Wrap(err, "reading tag message")
+	}
+	if a == nil {
+		return desc, nil
+	}
+	intro := a.Introduction(viper.GetBool("tag_message.tagger"))
+	if intro == "" {
+		return desc, nil
+	}
+	return intro + "\n\n" + desc, nil
+}
+
+// verifyTag checks the signature of the tag if it is enabled, and adds the
+// signer below the notes.
+func verifyTag(ctx context.Context, g *commit.Git, tag, desc string) (string, error) {
+	if !viper.GetBool("verify_tag.enabled") {
+		return desc, nil
+	}
+	minTrust, err := commit.ParseTrustLevel(viper.GetString("verify_tag.min_trust"))
+	if err != nil {
+		return "", errors.Wrap(err, "verify_tag.min_trust")
+	}
+	signer, err := g.VerifyTag(ctx, tag, viper.GetString("verify_tag.allowed_signers"), minTrust)
+	if err != nil {
+		return "", errors.Wrap(err, "refusing to publish the release")
+	}
+	return fmt.Sprintf("%s\n\n_The %s tag is signed by %s._", desc, tag, signer), nil
+}
@@ -0,0 +1,145 @@
+package credential
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// App authenticates as a GitHub App installation. It signs a JWT with the
+// private key of the app, and exchanges it for an installation token.
+type App struct {
+	// ID is the app ID or the client ID of the app.
+	ID             string
+	InstallationID int64
+	// PrivateKey is the PEM encoded RSA private key of the app.
+	PrivateKey []byte
+	// BaseURL is the address of the GitHub API, and defaults to
+	// https://api.github.com if empty.
+	BaseURL string
+	// Client is used for the requests. The http.DefaultClient is used if nil.
+	Client *http.Client
+	// Now returns the current time, and defaults to time.Now.
+	Now func() time.Time
+}
+
+// Name returns the name of the provider.
+func (a App) Name() string {
+	return "GitHub App " + a.ID
+}
+
+// Token returns a new installation token.
+func (a App) Token(ctx context.Context) (string, error) {
+	jwt, err := a.JWT()
+	if err != nil {
+		return "", err
+	}
+	base := a.BaseURL
+	if base == "" {
+		base = "https://api.github.com"
+	}
+	url := fmt.Sprintf("%s/app/installations/%d/access_tokens", strings.TrimSuffix(base, "/"), a.InstallationID)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, http.NoBody)
+	if err != nil {
+		return "", errors.Wrap(err, "creating request")
+	}
+	req.Header.Set("Authorization", "Bearer "+jwt)
+	req.Header.Set("Accept", "application/vnd.github+json")
+
+	client := a.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", errors.Wrap(err, "getting installation token")
+	}
+	// nolint:errcheck // it's ok.
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		// nolint:errcheck // only used for the error message.
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return "", fmt.Errorf("getting installation token: %s: %s", resp.Status, bytes.TrimSpace(msg))
+	}
+	var v struct {
+		Token string `json:"token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&v); err != nil {
+		return "", errors.Wrap(err, "decoding installation token")
+	}
+	if v.Token == "" {
+		return "", errors.New("the installation token is empty")
+	}
+	return v.Token, nil
+}
+
+// JWT returns a JSON Web Token signed with the private key, which is valid for
+// nine minutes. The issue time is set a minute in the past to allow for clock
+// drift.
+func (a App) JWT() (string, error) {
+	key, err := parsePrivateKey(a.PrivateKey)
+	if err != nil {
+		return "", err
+	}
+	now := time.Now
+	if a.Now != nil {
+		now = a.Now
+	}
+	t := now()
+	header := `{"alg":"RS256","typ":"JWT"}`
+	claims, err := json.Marshal(struct {
+		IssuedAt  int64  `json:"iat"`
+		ExpiresAt int64  `json:"exp"`
+		Issuer    string `json:"iss"`
+	}{
+		IssuedAt:  t.Add(-time.Minute).Unix(),
+		ExpiresAt: t.Add(9 * time.Minute).Unix(),
+		Issuer:    a.ID,
+	})
+	if err != nil {
+		return "", errors.Wrap(err, "marshalling claims")
+	}
+
+	enc := base64.RawURLEncoding
+	unsigned := enc.EncodeToString([]byte(header)) + "." + enc.EncodeToString(claims)
+	sum := sha256.Sum256([]byte(unsigned))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, sum[:])
+	if err != nil {
+		return "", errors.Wrap(err, "signing token")
+	}
+	return unsigned + "." + enc.EncodeToString(sig), nil
+}
+
+// parsePrivateKey parses the PKCS #1 or PKCS #8 RSA private key in PEM form.
+func parsePrivateKey(data []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("the private key is not in PEM format")
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, errors.Wrap(err, "parsing private key")
+	}
+	rsaKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("the private key is a %T, not an RSA key", key)
+	}
+	return rsaKey, nil
+}
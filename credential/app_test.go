@@ -0,0 +1,139 @@
+package credential_test
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/arsham/gitrelease/commit"
+	"github.com/arsham/gitrelease/credential"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApp(t *testing.T) {
+	t.Parallel()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	pkcs1 := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+	pkcs8 := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+	now := time.Date(2022, 5, 14, 12, 0, 0, 0, time.UTC)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/app/installations/42/access_tokens" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		jwt := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		parts := strings.Split(jwt, ".")
+		if len(parts) != 3 {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+		assert.NoError(t, err)
+		sum := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+		if err := rsa.VerifyPKCS1v15(&key.PublicKey, crypto.SHA256, sum[:], sig); err != nil {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, `{"message": "A JSON web token could not be decoded"}`)
+			return
+		}
+		claims, err := base64.RawURLEncoding.DecodeString(parts[1])
+		assert.NoError(t, err)
+		var v map[string]interface{}
+		assert.NoError(t, json.Unmarshal(claims, &v))
+		assert.Equal(t, "1234", v["iss"])
+		assert.EqualValues(t, now.Add(-time.Minute).Unix(), v["iat"])
+		assert.EqualValues(t, now.Add(9*time.Minute).Unix(), v["exp"])
+
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, `{"token": "ghs_installation", "expires_at": "2022-05-14T13:00:00Z"}`)
+	}))
+	t.Cleanup(srv.Close)
+
+	tcs := map[string]struct {
+		installation int64
+		key          []byte
+		want         string
+		wantErr      string
+	}{
+		"pkcs1":     {installation: 42, key: pkcs1, want: "ghs_installation"},
+		"pkcs8":     {installation: 42, key: pkcs8, want: "ghs_installation"},
+		"wrong key": {installation: 42, key: otherKey(t), wantErr: "401"},
+		"not found": {installation: 43, key: pkcs1, wantErr: "404"},
+		"not pem":   {installation: 42, key: []byte("key"), wantErr: "PEM"},
+	}
+	for name, tc := range tcs {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			a := credential.App{
+				ID:             "1234",
+				InstallationID: tc.installation,
+				PrivateKey:     tc.key,
+				BaseURL:        srv.URL,
+				Now:            func() time.Time { return now },
+			}
+			got, err := a.Token(context.Background())
+			if tc.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func otherKey(t *testing.T) []byte {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+}
+
+func TestAppRelease(t *testing.T) {
+	t.Parallel()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	pemKey := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+
+	var auth string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/app/installations/42/access_tokens":
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprint(w, `{"token": "ghs_installation"}`)
+		case "/repos/arsham/gitrelease/releases":
+			auth = r.Header.Get("Authorization")
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprint(w, `{}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	a := credential.App{ID: "1234", InstallationID: 42, PrivateKey: pemKey, BaseURL: srv.URL}
+	token, err := a.Token(context.Background())
+	require.NoError(t, err)
+	g := commit.Git{BaseURL: srv.URL}
+	err = g.Release(context.Background(), token, "arsham", "gitrelease", "v0.1.0", "desc")
+	require.NoError(t, err)
+	assert.Equal(t, "Bearer ghs_installation", auth)
+}
@@ -0,0 +1,100 @@
+// Package credential finds the GitHub token from the environment, the files of
+// other tools and git's credential helpers.
+package credential
+
+import (
+	"context"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ErrNotFound is returned when none of the providers have a token.
+var ErrNotFound = errors.New("no GitHub token found")
+
+// A Provider looks up a GitHub token. It returns an empty string if it doesn't
+// have any.
+type Provider interface {
+	// Name describes where the token is looked up.
+	Name() string
+	Token(ctx context.Context) (string, error)
+}
+
+// Chain asks the providers in order, and returns the first token found.
+type Chain []Provider
+
+// Token returns the first token found, and the name of its provider. It
+// returns ErrNotFound if none of the providers have a token.
+func (c Chain) Token(ctx context.Context) (token, name string, err error) {
+	for _, p := range c {
+		token, err := p.Token(ctx)
+		if err != nil {
+			return "", "", errors.Wrap(err, p.Name())
+		}
+		if token != "" {
+			return token, p.Name(), nil
+		}
+	}
+	return "", "", ErrNotFound
+}
+
+// Default returns the chain of the token file, if not empty, followed by the
+// GITHUB_TOKEN and GH_TOKEN environment variables, the gh CLI hosts file, the
+// ~/.netrc file and git's credential helpers for the host, e.g. github.com.
+func Default(tokenFile, host, dir string) Chain {
+	var chain Chain
+	if tokenFile != "" {
+		chain = append(chain, File{Path: tokenFile})
+	}
+	return append(chain,
+		Env{Names: []string{"GITHUB_TOKEN", "GH_TOKEN"}},
+		GHHosts{Host: host},
+		Netrc{Host: host},
+		GitCredential{Host: host, Dir: dir},
+	)
+}
+
+// Env reads the token from the first environment variable that is set.
+type Env struct {
+	Names []string
+}
+
+// Name returns the names of the variables.
+func (e Env) Name() string {
+	return strings.Join(e.Names, "/")
+}
+
+// Token returns the value of the first variable that is not empty.
+func (e Env) Token(context.Context) (string, error) {
+	for _, name := range e.Names {
+		if v := strings.TrimSpace(os.Getenv(name)); v != "" {
+			return v, nil
+		}
+	}
+	return "", nil
+}
+
+// File reads the token from the contents of a file. It is an error if the
+// file doesn't exist or is empty.
+type File struct {
+	Path string
+}
+
+// Name returns the path of the file.
+func (f File) Name() string {
+	return f.Path
+}
+
+// Token returns the contents of the file without the surrounding spaces.
+func (f File) Token(context.Context) (string, error) {
+	b, err := os.ReadFile(f.Path)
+	if err != nil {
+		return "", errors.Wrap(err, "reading token file")
+	}
+	token := strings.TrimSpace(string(b))
+	if token == "" {
+		return "", errors.New("token file is empty")
+	}
+	return token, nil
+}
@@ -0,0 +1,140 @@
+package credential_test
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path"
+	"testing"
+
+	"github.com/arsham/gitrelease/credential"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fixed struct {
+	name  string
+	token string
+	err   error
+}
+
+func (f fixed) Name() string                          { return f.name }
+func (f fixed) Token(context.Context) (string, error) { return f.token, f.err }
+
+func writeFile(t *testing.T, name, contents string) string {
+	t.Helper()
+	p := path.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(p, []byte(contents), 0o600))
+	return p
+}
+
+func TestChain(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	chain := credential.Chain{fixed{name: "a"}, fixed{name: "b", token: "tb"}, fixed{name: "c", token: "tc"}}
+	token, name, err := chain.Token(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "tb", token)
+	assert.Equal(t, "b", name)
+
+	_, _, err = credential.Chain{fixed{name: "a"}}.Token(ctx)
+	assert.ErrorIs(t, err, credential.ErrNotFound)
+
+	_, _, err = credential.Chain{fixed{name: "a", err: assert.AnError}, fixed{name: "b", token: "tb"}}.Token(ctx)
+	assert.ErrorIs(t, err, assert.AnError)
+}
+
+func TestEnv(t *testing.T) {
+	t.Setenv("GITRELEASE_TEST_A", "")
+	t.Setenv("GITRELEASE_TEST_B", " tb\n")
+	e := credential.Env{Names: []string{"GITRELEASE_TEST_A", "GITRELEASE_TEST_B"}}
+	got, err := e.Token(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "tb", got)
+	assert.Equal(t, "GITRELEASE_TEST_A/GITRELEASE_TEST_B", e.Name())
+}
+
+func TestFile(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	got, err := credential.File{Path: writeFile(t, "token", "secret\n")}.Token(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "secret", got)
+
+	_, err = credential.File{Path: writeFile(t, "token", "\n")}.Token(ctx)
+	assert.Error(t, err)
+
+	_, err = credential.File{Path: path.Join(t.TempDir(), "missing")}.Token(ctx)
+	assert.Error(t, err)
+}
+
+func TestGHHosts(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	hosts := writeFile(t, "hosts.yml", `github.com:
+    user: arsham
+    oauth_token: gho_secret
+    git_protocol: ssh
+github.example.com:
+    oauth_token: other
+`)
+	got, err := credential.GHHosts{Path: hosts, Host: "github.com"}.Token(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "gho_secret", got)
+
+	got, err = credential.GHHosts{Path: hosts, Host: "gitlab.com"}.Token(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, got)
+
+	got, err = credential.GHHosts{Path: path.Join(t.TempDir(), "missing"), Host: "github.com"}.Token(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, got)
+
+	_, err = credential.GHHosts{Path: writeFile(t, "hosts.yml", "github.com: [\n"), Host: "github.com"}.Token(ctx)
+	assert.Error(t, err)
+}
+
+func TestNetrc(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	tcs := map[string]struct {
+		contents string
+		want     string
+	}{
+		"lines":   {contents: "machine github.com\n  login arsham\n  password secret\n", want: "secret"},
+		"inline":  {contents: "machine example.com login a password b machine github.com login arsham password secret", want: "secret"},
+		"api":     {contents: "machine api.github.com login arsham password api-secret\n", want: "api-secret"},
+		"default": {contents: "default login arsham password nope\n"},
+		"macro":   {contents: "macdef init\nmachine github.com password nope\n\nmachine example.com password b\n"},
+		"missing": {contents: "machine example.com login a password b\n"},
+	}
+	for name, tc := range tcs {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			n := credential.Netrc{Path: writeFile(t, ".netrc", tc.contents), Host: "github.com"}
+			got, err := n.Token(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestGitCredential(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	dir := t.TempDir()
+	for _, args := range [][]string{
+		{"init"},
+		{"config", "credential.helper", "!f() { test \"$1\" = get && echo username=arsham && echo password=from-helper; }; f"},
+	} {
+		cmd := exec.CommandContext(ctx, "git", args...)
+		cmd.Dir = dir
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+
+	got, err := credential.GitCredential{Host: "github.com", Dir: dir}.Token(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "from-helper", got)
+}
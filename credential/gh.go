@@ -0,0 +1,65 @@
+package credential
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// GHHosts reads the token the gh CLI stores in its hosts.yml file. The newer
+// versions of gh store the tokens in the system's keyring, which is not
+// supported.
+type GHHosts struct {
+	// Path is the location of the hosts.yml file. If empty, it is looked up
+	// in $GH_CONFIG_DIR, $XDG_CONFIG_HOME/gh or ~/.config/gh.
+	Path string
+	Host string
+}
+
+// Name returns the name of the provider.
+func (g GHHosts) Name() string {
+	return "gh hosts file"
+}
+
+func (g GHHosts) path() string {
+	if g.Path != "" {
+		return g.Path
+	}
+	if dir := os.Getenv("GH_CONFIG_DIR"); dir != "" {
+		return filepath.Join(dir, "hosts.yml")
+	}
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, "gh", "hosts.yml")
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".config", "gh", "hosts.yml")
+}
+
+// Token returns the oauth_token of the Host. It returns an empty string if the
+// file doesn't exist.
+func (g GHHosts) Token(context.Context) (string, error) {
+	path := g.path()
+	if path == "" {
+		return "", nil
+	}
+	b, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", errors.Wrap(err, "reading gh hosts file")
+	}
+	var hosts map[string]struct {
+		OAuthToken string `yaml:"oauth_token"`
+	}
+	if err := yaml.Unmarshal(b, &hosts); err != nil {
+		return "", errors.Wrapf(err, "parsing %s", path)
+	}
+	return hosts[g.Host].OAuthToken, nil
+}
@@ -0,0 +1,43 @@
+package credential
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// GitCredential asks git's credential helpers for the password of the host,
+// as `git credential fill` does. It never prompts the user.
+type GitCredential struct {
+	Host string
+	// Dir is where git runs, so the repository's settings are used.
+	Dir string
+}
+
+// Name returns the name of the provider.
+func (g GitCredential) Name() string {
+	return "git credential"
+}
+
+// Token returns the password the helpers have for the host. It returns an
+// empty string if git is not installed or the helpers don't have any.
+func (g GitCredential) Token(ctx context.Context) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", "credential", "fill")
+	cmd.Dir = g.Dir
+	cmd.Stdin = strings.NewReader("protocol=https\nhost=" + g.Host + "\n\n")
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0", "GIT_ASKPASS=", "SSH_ASKPASS=")
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	if err := cmd.Run(); err != nil {
+		// Git fails when it can't find a password without prompting.
+		return "", ctx.Err()
+	}
+	for _, line := range strings.Split(out.String(), "\n") {
+		if strings.HasPrefix(line, "password=") {
+			return strings.TrimPrefix(line, "password="), nil
+		}
+	}
+	return "", nil
+}
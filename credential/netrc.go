@@ -0,0 +1,99 @@
+package credential
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Netrc reads the password of the host, or the API host of it, from the
+// .netrc file.
+type Netrc struct {
+	// Path is the location of the file. If empty, it is $NETRC or ~/.netrc,
+	// and ~/_netrc on Windows.
+	Path string
+	Host string
+}
+
+// Name returns the name of the provider.
+func (n Netrc) Name() string {
+	return "netrc"
+}
+
+func (n Netrc) path() string {
+	if n.Path != "" {
+		return n.Path
+	}
+	if p := os.Getenv("NETRC"); p != "" {
+		return p
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	if runtime.GOOS == "windows" {
+		return filepath.Join(home, "_netrc")
+	}
+	return filepath.Join(home, ".netrc")
+}
+
+// Token returns the password of the machine matching the Host or "api." +
+// Host. It returns an empty string if the file doesn't exist.
+func (n Netrc) Token(context.Context) (string, error) {
+	path := n.path()
+	if path == "" {
+		return "", nil
+	}
+	b, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", errors.Wrap(err, "reading netrc file")
+	}
+	passwords := parseNetrc(string(b))
+	if p := passwords[n.Host]; p != "" {
+		return p, nil
+	}
+	return passwords["api."+n.Host], nil
+}
+
+// parseNetrc returns the passwords of the machines in the netrc contents. The
+// macros are skipped.
+func parseNetrc(contents string) map[string]string {
+	passwords := make(map[string]string)
+	var machine string
+	var inMacro bool
+	for _, line := range strings.Split(contents, "\n") {
+		if inMacro {
+			// A macro ends with an empty line.
+			inMacro = strings.TrimSpace(line) != ""
+			continue
+		}
+		fields := strings.Fields(line)
+		for i := 0; i < len(fields); i++ {
+			switch fields[i] {
+			case "machine":
+				if i+1 < len(fields) {
+					machine = fields[i+1]
+					i++
+				}
+			case "default":
+				machine = ""
+			case "password":
+				if i+1 < len(fields) && machine != "" {
+					passwords[machine] = fields[i+1]
+					i++
+				}
+			case "macdef":
+				inMacro = true
+				i = len(fields)
+			}
+		}
+	}
+	return passwords
+}
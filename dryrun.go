@@ -0,0 +1,137 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+
+	"github.com/arsham/gitrelease/commit"
+	"github.com/arsham/gitrelease/notify"
+	"github.com/spf13/viper"
+)
+
+// dryRun prints the difference between the existing release of the tag and
+// the new notes, and the API calls that would be made for publishing them.
+// It doesn't change anything on GitHub.
+func dryRun(ctx context.Context, w io.Writer, g *commit.Git, token, user, repo, tag, desc string, milestones []commit.Milestone, milestone *commit.Milestone, hooks []notify.Notifier) error {
+	existing, err := g.ReleaseByTag(ctx, token, user, repo, tag)
+	if err != nil {
+		return err
+	}
+
+	var current string
+	if existing == nil {
+		fmt.Fprintf(w, "%s has no release yet.\n\n", tag)
+	} else {
+		current = existing.Body
+		fmt.Fprintf(w, "%s has a release at %s.\n\n", tag, existing.HTMLURL)
+	}
+
+	diff := diffText(current, desc)
+	if diff == "" {
+		fmt.Fprint(w, "The notes are the same as the existing release.\n\n")
+	} else {
+		fmt.Fprintln(w, diff)
+	}
+
+	fmt.Fprintln(w, "API calls:")
+	if viper.GetBool("milestones.enabled") {
+		fmt.Fprintf(w, "  made:    GET /repos/%s/%s/milestones?state=all\n", user, repo)
+	}
+	fmt.Fprintf(w, "  made:    GET /repos/%s/%s/releases/tags/%s\n", user, repo, url.PathEscape(tag))
+	if existing != nil {
+		fmt.Fprintln(w, "  skipped: publishing would fail because the release already exists")
+		return nil
+	}
+	call, err := commit.ReleaseCall(user, repo, tag, desc)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "  pending: %s\n", call)
+
+	if err := dryRunMilestone(ctx, w, g, token, user, repo, milestones, milestone); err != nil {
+		return err
+	}
+	dryRunAnnounce(w, g, token, user, repo, tag, desc)
+	dryRunNotify(w, hooks)
+	return nil
+}
+
+// dryRunMilestone prints the calls closeMilestone would make. The open issues
+// of the milestone are read for finding them.
+func dryRunMilestone(ctx context.Context, w io.Writer, g *commit.Git, token, user, repo string, milestones []commit.Milestone, m *commit.Milestone) error {
+	if m == nil {
+		return nil
+	}
+	issues, err := g.MilestoneIssues(ctx, token, user, repo, m.Number)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "  made:    GET /repos/%s/%s/issues?milestone=%d&state=open\n", user, repo, m.Number)
+	if len(issues) > 0 {
+		next := commit.NextMilestone(milestones, m.Title)
+		if next == nil {
+			fmt.Fprintf(w, "  skipped: milestone %s has %d open issues and there is no milestone after it, it would be left open\n", m.Title, len(issues))
+			return nil
+		}
+		for _, issue := range issues {
+			fmt.Fprintf(w, "  pending: %s\n", commit.SetMilestoneCall(user, repo, issue, next.Number))
+		}
+	}
+	if m.Open() {
+		fmt.Fprintf(w, "  pending: %s\n", commit.CloseMilestoneCall(user, repo, m.Number))
+	}
+	return nil
+}
+
+// dryRunAnnounce prints the calls announce would make for the issues that are
+// not commented on yet.
+func dryRunAnnounce(w io.Writer, g *commit.Git, token, user, repo, tag, desc string) {
+	if !viper.GetBool("comments.enabled") {
+		return
+	}
+	a := announcer(g, token, user, repo)
+	for _, n := range commit.IssueRefs(desc) {
+		fmt.Fprintf(w, "  pending: GET /repos/%s/%s/issues/%d/comments\n", user, repo, n)
+		for _, call := range a.Calls(tag, releaseURL(user, repo, tag), n) {
+			fmt.Fprintf(w, "  pending: %s (unless #%d is already commented on)\n", call, n)
+		}
+	}
+}
+
+// dryRunNotify prints the posts notifyRelease would make to the hooks. The
+// paths of their addresses are left out, as they often hold the secrets.
+func dryRunNotify(w io.Writer, hooks []notify.Notifier) {
+	for _, n := range hooks {
+		switch h := n.(type) {
+		case notify.Webhook:
+			fmt.Fprintf(w, "  pending: POST %s (%s webhook %s)\n", redactURL(h.URL), h.Kind, h.Name)
+		case notify.SignedWebhook:
+			fmt.Fprintf(w, "  pending: POST %s (signed webhook %s)\n", redactURL(h.URL), h.Name)
+		}
+	}
+}
+
+// redactURL returns the scheme and the host of the address.
+func redactURL(addr string) string {
+	u, err := url.Parse(addr)
+	if err != nil || u.Host == "" {
+		return "[REDACTED]"
+	}
+	return u.Scheme + "://" + u.Host + "/[REDACTED]"
+}
+
+// releaseURL returns the address of the release page of the tag.
+func releaseURL(user, repo, tag string) string {
+	return fmt.Sprintf("https://github.com/%s/%s/releases/tag/%s", user, repo, tag)
+}
+
+// diffText returns the unified diff between the current and the updated
+// texts. It returns an empty string if they are the same. The bodies edited on
+// GitHub have CRLF line endings, which are not counted as differences.
+func diffText(current, updated string) string {
+	current = strings.ReplaceAll(current, "\r\n", "\n")
+	return commit.UnifiedDiff("current", "updated", current, updated)
+}
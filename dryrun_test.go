@@ -0,0 +1,42 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestDiffText(t *testing.T) {
+	t.Parallel()
+	tcs := map[string]struct {
+		current string
+		updated string
+		want    string
+	}{
+		"same": {
+			current: "### Fix\n\n- Something",
+			updated: "### Fix\n\n- Something",
+			want:    "",
+		},
+		"crlf": {
+			current: "### Fix\r\n\r\n- Something",
+			updated: "### Fix\n\n- Something",
+			want:    "",
+		},
+		"crlf changed": {
+			current: "### Fix\r\n\r\n- Something",
+			updated: "### Fix\n\n- Other",
+			want:    "--- current\n+++ updated\n@@ -1,3 +1,3 @@\n ### Fix\n \n-- Something\n+- Other\n",
+		},
+	}
+	for name, tc := range tcs {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			got := diffText(tc.current, tc.updated)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("(-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/arsham/gitrelease/commit"
+	"github.com/pkg/errors"
+)
+
+// editFile returns the path of the file the release notes of the tag are
+// edited in. The file is kept inside the git directory when publishing fails,
+// so the edit is not lost and is used in the next attempt.
+func editFile(ctx context.Context, g *commit.Git, tag string) (string, error) {
+	name := "RELEASE_EDITMSG_" + strings.ReplaceAll(tag, "/", "_")
+	return g.GitPath(ctx, name)
+}
+
+// editRelease opens the notes of the tag in the editor, and returns the edited
+// notes and the file they are kept in for the next attempt. The edit of a dry
+// run is removed after it is read and no file is returned, unless the edit was
+// saved by a previous attempt.
+func editRelease(ctx context.Context, w io.Writer, g *commit.Git, editor, tag, notes string, dryRun bool) (edited, draft string, err error) {
+	name, err := editFile(ctx, g, tag)
+	if err != nil {
+		return "", "", err
+	}
+	_, err = os.Stat(name)
+	saved := !os.IsNotExist(err)
+	edited, err = editNotes(ctx, w, editor, name, notes)
+	if !dryRun || saved {
+		return edited, name, err
+	}
+	// The empty notes have already removed the file.
+	if rerr := os.Remove(name); rerr != nil && !os.IsNotExist(rerr) {
+		return "", "", errors.Wrap(rerr, "removing the edit of the dry run")
+	}
+	return edited, "", err
+}
+
+// editor returns the user's editor.
+func editor() string {
+	if editor := os.Getenv("VISUAL"); editor != "" {
+		return editor
+	}
+	if editor := os.Getenv("EDITOR"); editor != "" {
+		return editor
+	}
+	return "vi"
+}
+
+// editNotes opens the notes in the editor and returns the edited result. If
+// there is a saved edit from a previous attempt in the file, it is used
+// instead of the notes.
+func editNotes(ctx context.Context, w io.Writer, editor, name, notes string) (string, error) {
+	if _, err := os.Stat(name); os.IsNotExist(err) {
+		if err := os.WriteFile(name, []byte(notes+"\n"), 0o600); err != nil {
+			return "", errors.Wrap(err, "writing notes for editing")
+		}
+	} else {
+		fmt.Fprintf(w, "using the saved edit from the previous attempt in %s\n", name)
+	}
+
+	// The editor is run through the shell to support values with arguments,
+	// e.g. "code --wait".
+	// nolint:gosec // the editor is chosen by the user.
+	cmd := exec.CommandContext(ctx, "sh", "-c", editor+` "$@"`, editor, name)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return "", errors.Wrapf(err, "running editor %q", editor)
+	}
+
+	content, err := os.ReadFile(name)
+	if err != nil {
+		return "", errors.Wrap(err, "reading edited notes")
+	}
+	edited := strings.TrimSpace(string(content))
+	if edited == "" {
+		// nolint:errcheck // there is nothing to keep.
+		os.Remove(name)
+		return "", errors.New("aborting release due to empty notes")
+	}
+	return edited, nil
+}
+
+// keepEdit tells the user where the edit is kept for the next attempt if the
+// err is not nil. The err is returned as is if there is no draft.
+func keepEdit(err error, draft string) error {
+	if err == nil || draft == "" {
+		return err
+	}
+	return errors.Wrapf(err, "your edit is kept in %s for the next attempt", draft)
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/arsham/gitrelease/commit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEditNotes(t *testing.T) {
+	t.Parallel()
+	notes := "### Fix\n\n- Something"
+	tcs := map[string]struct {
+		editor  string
+		saved   string
+		want    string
+		wantOut string
+		wantErr bool
+	}{
+		"unchanged": {
+			editor: "true",
+			want:   notes,
+		},
+		"edited": {
+			editor: "sed -i -e s/Something/Other/",
+			want:   "### Fix\n\n- Other",
+		},
+		"saved": {
+			editor:  "true",
+			saved:   "### Fix\n\n- Saved\n",
+			want:    "### Fix\n\n- Saved",
+			wantOut: "using the saved edit from the previous attempt in ",
+		},
+		"empty": {
+			editor:  "cp /dev/null",
+			wantErr: true,
+		},
+		"editor fails": {
+			editor:  "false",
+			wantErr: true,
+		},
+	}
+	for name, tc := range tcs {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			file := filepath.Join(t.TempDir(), "RELEASE_EDITMSG")
+			if tc.saved != "" {
+				require.NoError(t, os.WriteFile(file, []byte(tc.saved), 0o600))
+			}
+			buf := &bytes.Buffer{}
+			got, err := editNotes(context.Background(), buf, tc.editor, file, notes)
+			if tc.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+			if tc.wantOut != "" {
+				assert.Equal(t, tc.wantOut+file+"\n", buf.String())
+			} else {
+				assert.Empty(t, buf.String())
+			}
+		})
+	}
+}
+
+func TestEditRelease(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	dir := createGitRepo(t)
+	g := &commit.Git{Dir: dir}
+	name := filepath.Join(dir, ".git", "RELEASE_EDITMSG_v0.1.0")
+	notes := "### Fix\n\n- Something"
+	edit := "sed -i -e s/Something/Other/"
+
+	got, draft, err := editRelease(ctx, &bytes.Buffer{}, g, edit, "v0.1.0", notes, true)
+	require.NoError(t, err)
+	assert.Equal(t, "### Fix\n\n- Other", got)
+	assert.Empty(t, draft)
+	assert.NoFileExists(t, name, "the edit of the dry run is removed")
+
+	buf := &bytes.Buffer{}
+	got, draft, err = editRelease(ctx, buf, g, "true", "v0.1.0", notes, false)
+	require.NoError(t, err)
+	assert.Equal(t, notes, got, "the edit of the dry run is not reused")
+	assert.Empty(t, buf.String())
+	assert.Equal(t, name, draft)
+	assert.FileExists(t, name)
+
+	// The saved edit of a failed attempt is used and kept by the dry runs.
+	got, draft, err = editRelease(ctx, &bytes.Buffer{}, g, edit, "v0.1.0", "### Fix\n\n- New", true)
+	require.NoError(t, err)
+	assert.Equal(t, "### Fix\n\n- Other", got)
+	assert.Equal(t, name, draft)
+	content, err := os.ReadFile(name)
+	require.NoError(t, err)
+	assert.Equal(t, "### Fix\n\n- Other\n", string(content))
+
+	err = keepEdit(assert.AnError, draft)
+	assert.ErrorIs(t, err, assert.AnError)
+	assert.Contains(t, err.Error(), "your edit is kept in "+name)
+	assert.Equal(t, assert.AnError, keepEdit(assert.AnError, ""))
+	assert.NoError(t, keepEdit(nil, draft))
+}
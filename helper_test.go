@@ -0,0 +1,42 @@
+package main
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func createGitRepo(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	runGit(t, dir, "init")
+	runGit(t, dir, "config", "user.email", "arsham@github.com")
+	runGit(t, dir, "config", "user.name", "arsham")
+	return dir
+}
+
+func commitChanges(t *testing.T, dir, msg string) {
+	t.Helper()
+	runGit(t, dir, "commit", "--allow-empty", "--no-gpg-sign", "-m", msg)
+}
+
+func runGit(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.CommandContext(context.Background(), "git", args...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(out))
+	return strings.TrimSpace(string(out))
+}
+
+func createFile(t *testing.T, dir, filename, content string) string {
+	t.Helper()
+	name := filepath.Join(dir, filename)
+	require.NoError(t, os.WriteFile(name, []byte(content), 0o644))
+	return name
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"fmt"
+	"os/exec"
+
+	"github.com/arsham/gitrelease/commit"
+	"github.com/pkg/errors"
+)
+
+// hint returns a suggestion for fixing the error, or an empty string if there
+// is none.
+func hint(err error) string {
+	switch {
+	case err == nil:
+		return ""
+	case errors.Is(err, commit.ErrReleaseExists):
+		return "run with --dry-run to see the difference with the existing release, or delete it on GitHub and run again"
+	case errors.Is(err, commit.ErrNotRepository):
+		return "run gitrelease inside a git repository"
+	case errors.Is(err, commit.ErrNoTags):
+		return "the repository has no tags yet, tag the commit to release, e.g. git tag -a v0.1.0, and run again"
+	case errors.Is(err, commit.ErrUnknownRemote):
+		return fmt.Sprintf("the %s remote is not configured, use --remote to choose one of the remotes git remote -v lists", remote)
+	case errors.Is(err, commit.ErrTagNotFound):
+		return fmt.Sprintf("check the tag name with git tag --list, and push it to GitHub with git push %s <tag>", remote)
+	case errors.Is(err, exec.ErrNotFound):
+		return "install git, or use --git-backend go to read the repository without it"
+	case errors.Is(err, commit.ErrUnauthorized):
+		return "the token is invalid or expired, check GITHUB_TOKEN, --token-file or the GitHub App settings"
+	case errors.Is(err, commit.ErrForbidden):
+		return "the token needs the repo scope, or the contents write permission for fine-grained tokens and GitHub Apps"
+	}
+	return ""
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/arsham/gitrelease/commit"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// hookMarker identifies the hooks written by gitrelease, so they are not
+// confused with the hooks written by users or other tools.
+const hookMarker = "# Installed by gitrelease."
+
+const commitMsgHook = `#!/bin/sh
+` + hookMarker + ` Remove with: gitrelease hooks uninstall
+exec gitrelease lint --file "$1"
+`
+
+var (
+	hooksForce bool
+
+	hooksCmd = &cobra.Command{
+		Use:   "hooks",
+		Short: "Manage the git hooks that validate commit messages",
+	}
+
+	hooksInstallCmd = &cobra.Command{
+		Use:          "install",
+		Short:        "Install a commit-msg hook that lints the commit messages",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			g, err := newGit()
+			if err != nil {
+				return err
+			}
+			return installHook(cmd.Context(), cmd.OutOrStdout(), g, hooksForce)
+		},
+	}
+
+	hooksUninstallCmd = &cobra.Command{
+		Use:          "uninstall",
+		Short:        "Remove the commit-msg hook installed by gitrelease",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			g, err := newGit()
+			if err != nil {
+				return err
+			}
+			return uninstallHook(cmd.Context(), cmd.OutOrStdout(), g)
+		},
+	}
+)
+
+func init() {
+	hooksInstallCmd.Flags().BoolVar(&hooksForce, "force", false, "overwrite an existing commit-msg hook")
+	hooksCmd.AddCommand(hooksInstallCmd, hooksUninstallCmd)
+	rootCmd.AddCommand(hooksCmd)
+}
+
+// installHook writes the commit-msg hook. An existing hook that was not
+// installed by gitrelease is only overwritten if force is true.
+func installHook(ctx context.Context, w io.Writer, g *commit.Git, force bool) error {
+	name, err := commitMsgHookPath(ctx, g)
+	if err != nil {
+		return err
+	}
+	ours, err := isOurHook(name)
+	if err != nil {
+		return err
+	}
+	if !ours && !force {
+		return fmt.Errorf("%s already exists, use --force to overwrite it", name)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+		return errors.Wrap(err, "creating hooks directory")
+	}
+	// nolint:gosec // hooks need to be executable.
+	if err := os.WriteFile(name, []byte(commitMsgHook), 0o755); err != nil {
+		return errors.Wrap(err, "writing hook")
+	}
+	// WriteFile doesn't change the permissions of existing files.
+	// nolint:gosec // hooks need to be executable.
+	if err := os.Chmod(name, 0o755); err != nil {
+		return errors.Wrap(err, "making hook executable")
+	}
+	fmt.Fprintf(w, "installed %s\n", name)
+	return nil
+}
+
+// uninstallHook removes the commit-msg hook if it was installed by gitrelease.
+func uninstallHook(ctx context.Context, w io.Writer, g *commit.Git) error {
+	name, err := commitMsgHookPath(ctx, g)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Lstat(name); os.IsNotExist(err) {
+		fmt.Fprintln(w, "no commit-msg hook installed")
+		return nil
+	}
+	ours, err := isOurHook(name)
+	if err != nil {
+		return err
+	}
+	if !ours {
+		return fmt.Errorf("%s was not installed by gitrelease", name)
+	}
+	if err := os.Remove(name); err != nil {
+		return errors.Wrap(err, "removing hook")
+	}
+	fmt.Fprintf(w, "removed %s\n", name)
+	return nil
+}
+
+// commitMsgHookPath returns the path of the commit-msg hook. The repository is
+// read with the chosen backend, so the hooks can be managed without git.
+func commitMsgHookPath(ctx context.Context, g *commit.Git) (string, error) {
+	dir, err := g.HooksDir(ctx)
+	if err != nil {
+		return "", errors.Wrap(err, "finding hooks directory")
+	}
+	return filepath.Join(dir, "commit-msg"), nil
+}
+
+// isOurHook returns true if the hook doesn't exist or was written by
+// gitrelease.
+func isOurHook(name string) (bool, error) {
+	content, err := os.ReadFile(name)
+	if os.IsNotExist(err) {
+		return true, nil
+	}
+	if err != nil {
+		return false, errors.Wrap(err, "reading hook")
+	}
+	return bytes.Contains(content, []byte(hookMarker)), nil
+}
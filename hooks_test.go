@@ -0,0 +1,98 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/arsham/gitrelease/commit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHooks(t *testing.T) {
+	t.Parallel()
+	for _, backend := range []commit.Backend{commit.BackendExec, commit.BackendGo} {
+		backend := backend
+		t.Run(string(backend), func(t *testing.T) {
+			t.Parallel()
+			t.Run("Install", testHooksInstall(backend))
+			t.Run("Force", testHooksForce(backend))
+			t.Run("HooksPath", testHooksHooksPath(backend))
+		})
+	}
+}
+
+func testHooksInstall(backend commit.Backend) func(t *testing.T) {
+	return func(t *testing.T) {
+		t.Parallel()
+		ctx := context.Background()
+		dir := createGitRepo(t)
+		g := &commit.Git{Dir: dir, Backend: backend}
+		name := filepath.Join(dir, ".git", "hooks", "commit-msg")
+
+		buf := &bytes.Buffer{}
+		require.NoError(t, uninstallHook(ctx, buf, g))
+		assert.Equal(t, "no commit-msg hook installed\n", buf.String())
+
+		buf.Reset()
+		require.NoError(t, installHook(ctx, buf, g, false))
+		assert.Equal(t, "installed "+name+"\n", buf.String())
+		info, err := os.Stat(name)
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0o755), info.Mode().Perm())
+		content, err := os.ReadFile(name)
+		require.NoError(t, err)
+		assert.Contains(t, string(content), hookMarker)
+
+		// Our own hook is replaced without --force.
+		require.NoError(t, installHook(ctx, &bytes.Buffer{}, g, false))
+
+		buf.Reset()
+		require.NoError(t, uninstallHook(ctx, buf, g))
+		assert.Equal(t, "removed "+name+"\n", buf.String())
+		assert.NoFileExists(t, name)
+	}
+}
+
+func testHooksForce(backend commit.Backend) func(t *testing.T) {
+	return func(t *testing.T) {
+		t.Parallel()
+		ctx := context.Background()
+		dir := createGitRepo(t)
+		g := &commit.Git{Dir: dir, Backend: backend}
+		name := createFile(t, filepath.Join(dir, ".git", "hooks"), "commit-msg", "#!/bin/sh\nexit 0\n")
+
+		err := installHook(ctx, &bytes.Buffer{}, g, false)
+		assert.EqualError(t, err, name+" already exists, use --force to overwrite it")
+		err = uninstallHook(ctx, &bytes.Buffer{}, g)
+		assert.EqualError(t, err, name+" was not installed by gitrelease")
+		content, err := os.ReadFile(name)
+		require.NoError(t, err)
+		assert.Equal(t, "#!/bin/sh\nexit 0\n", string(content), "the user's hook is kept")
+
+		require.NoError(t, installHook(ctx, &bytes.Buffer{}, g, true))
+		content, err = os.ReadFile(name)
+		require.NoError(t, err)
+		assert.Equal(t, commitMsgHook, string(content))
+	}
+}
+
+func testHooksHooksPath(backend commit.Backend) func(t *testing.T) {
+	return func(t *testing.T) {
+		t.Parallel()
+		ctx := context.Background()
+		dir := createGitRepo(t)
+		runGit(t, dir, "config", "core.hooksPath", "githooks")
+		g := &commit.Git{Dir: dir, Backend: backend}
+
+		buf := &bytes.Buffer{}
+		require.NoError(t, installHook(ctx, buf, g, false))
+		name := filepath.Join(dir, "githooks", "commit-msg")
+		assert.Equal(t, "installed "+name+"\n", buf.String())
+		assert.FileExists(t, name)
+		assert.NoFileExists(t, filepath.Join(dir, ".git", "hooks", "commit-msg"))
+	}
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/arsham/gitrelease/commit"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var (
+	lintFile string
+
+	lintCmd = &cobra.Command{
+		Use:   "lint [range]",
+		Short: "Check commit messages against the release notes conventions",
+		Long: `Checks the commit messages against the configured verbs and scopes, and reports
+the ones that would be listed under the Misc section.
+
+The range can be given as "tag1..tag2" or as a single revision, in which case
+the commits between it and HEAD are checked. If omitted, the commits since the
+latest tag are checked. Use --file to check a single message, for example from
+a commit-msg hook.`,
+		Args:         cobra.MaximumNArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			convention, err := commit.ConventionByName(viper.GetString("convention"))
+			if err != nil {
+				return err
+			}
+			linter := commit.Linter{
+				Convention: convention,
+				Verbs:      viper.GetStringSlice("lint.verbs"),
+				Scopes:     viper.GetStringSlice("lint.scopes"),
+			}
+			if lintFile != "" {
+				return lintMessageFile(cmd.OutOrStdout(), linter, lintFile)
+			}
+
+			g, err := newGit()
+			if err != nil {
+				return err
+			}
+			ctx := cmd.Context()
+			from, to := lintRange(ctx, g, args)
+			return lintCommits(ctx, cmd.OutOrStdout(), g, linter, from, to)
+		},
+	}
+)
+
+func init() {
+	lintCmd.Flags().StringVarP(&lintFile, "file", "f", "", `check the message in the file, use "-" for stdin`)
+	lintCmd.Flags().StringSlice("verbs", nil, "allowed verbs, e.g. Feature,Fix (default is all known verbs)")
+	lintCmd.Flags().StringSlice("scopes", nil, "allowed scopes (default is any scope)")
+	cobra.CheckErr(viper.BindPFlag("lint.verbs", lintCmd.Flags().Lookup("verbs")))
+	cobra.CheckErr(viper.BindPFlag("lint.scopes", lintCmd.Flags().Lookup("scopes")))
+	rootCmd.AddCommand(lintCmd)
+}
+
+func lintMessageFile(w io.Writer, linter commit.Linter, name string) error {
+	var (
+		msg []byte
+		err error
+	)
+	if name == "-" {
+		msg, err = io.ReadAll(os.Stdin)
+	} else {
+		msg, err = os.ReadFile(name)
+	}
+	if err != nil {
+		return errors.Wrap(err, "reading commit message")
+	}
+
+	diags := linter.Lint(commit.CleanMessage(string(msg)))
+	for _, d := range diags {
+		fmt.Fprintf(w, "%s:%s\n", name, d)
+	}
+	if len(diags) > 0 {
+		return errors.New("invalid commit message")
+	}
+	return nil
+}
+
+// lintRange returns the range of the commits the args select. The commits
+// since the latest tag are selected if there are no args.
+func lintRange(ctx context.Context, g *commit.Git, args []string) (from, to string) {
+	switch {
+	case len(args) == 0:
+		// No tags means all commits are checked.
+		from, _ = g.LatestTag(ctx) // nolint:errcheck // explained above.
+		return from, "HEAD"
+	case strings.Contains(args[0], ".."):
+		parts := strings.SplitN(args[0], "..", 2)
+		return parts[0], parts[1]
+	}
+	return args[0], "HEAD"
+}
+
+// lintCommits checks the messages of the commits between from and to as they
+// are read.
+func lintCommits(ctx context.Context, w io.Writer, g *commit.Git, linter commit.Linter, from, to string) error {
+	var total, failed int
+	err := g.EachCommit(ctx, from, to, func(c commit.Commit) error {
+		log := strings.TrimSpace(c.Message)
+		if log == "" {
+			return nil
+		}
+		total++
+		diags := linter.Lint(log)
+		if len(diags) == 0 {
+			return nil
+		}
+		failed++
+		title := strings.SplitN(log, "\n", 2)[0]
+		for _, d := range diags {
+			fmt.Fprintf(w, "%q:%s\n", title, d)
+		}
+		return nil
+	})
+	if err != nil {
+		return errors.Wrap(err, "getting commits")
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d commit messages have problems", failed, total)
+	}
+	return nil
+}
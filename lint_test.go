@@ -0,0 +1,109 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/arsham/gitrelease/commit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLintMessageFile(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	tcs := map[string]struct {
+		msg     string
+		want    string
+		wantErr bool
+	}{
+		"valid": {
+			msg: "feat(api): add x\n\nSome details.\n",
+		},
+		"comments": {
+			msg: "fix: the bug\n# Please enter the commit message for your changes.\n",
+		},
+		"no type": {
+			msg:     "add x\n",
+			want:    ":1: unknown type \"add\", it will be listed under Misc\n",
+			wantErr: true,
+		},
+	}
+	for name, tc := range tcs {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			file := createFile(t, dir, name, tc.msg)
+			buf := &bytes.Buffer{}
+			err := lintMessageFile(buf, commit.Linter{}, file)
+			if tc.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			want := ""
+			if tc.want != "" {
+				want = file + tc.want
+			}
+			assert.Equal(t, want, buf.String())
+		})
+	}
+
+	err := lintMessageFile(&bytes.Buffer{}, commit.Linter{}, filepath.Join(dir, "missing"))
+	assert.Error(t, err)
+}
+
+func TestLintRange(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	dir := createGitRepo(t)
+	commitChanges(t, dir, "feat: first")
+	g := &commit.Git{Dir: dir}
+
+	from, to := lintRange(ctx, g, nil)
+	assert.Equal(t, "", from, "all commits without tags")
+	assert.Equal(t, "HEAD", to)
+
+	runGit(t, dir, "tag", "v0.1.0")
+	tcs := map[string]struct {
+		args     []string
+		from, to string
+	}{
+		"latest tag": {from: "v0.1.0", to: "HEAD"},
+		"range":      {args: []string{"v0.0.1..v0.1.0"}, from: "v0.0.1", to: "v0.1.0"},
+		"revision":   {args: []string{"v0.0.1"}, from: "v0.0.1", to: "HEAD"},
+	}
+	for name, tc := range tcs {
+		from, to := lintRange(ctx, g, tc.args)
+		assert.Equal(t, tc.from, from, name)
+		assert.Equal(t, tc.to, to, name)
+	}
+}
+
+func TestLintCommits(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	dir := createGitRepo(t)
+	commitChanges(t, dir, "feat: first")
+	runGit(t, dir, "tag", "v0.1.0")
+	commitChanges(t, dir, "fix(api): second")
+	g := &commit.Git{Dir: dir}
+	linter := commit.Linter{Scopes: []string{"api"}}
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, lintCommits(ctx, buf, g, linter, "v0.1.0", "HEAD"))
+	assert.Empty(t, buf.String())
+
+	commitChanges(t, dir, "fix(cli): third")
+	commitChanges(t, dir, "no type")
+	err := lintCommits(ctx, buf, g, linter, "v0.1.0", "HEAD")
+	assert.EqualError(t, err, "2 of 3 commit messages have problems")
+	want := `"no type":1: unknown type "no", it will be listed under Misc` + "\n" +
+		`"fix(cli): third":1: scope "cli" is not allowed` + "\n"
+	assert.Equal(t, want, buf.String())
+
+	err = lintCommits(ctx, buf, g, linter, "v9.9.9", "HEAD")
+	assert.Error(t, err)
+}
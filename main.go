@@ -7,6 +7,7 @@ import (
 	"syscall"
 
 	"github.com/arsham/gitrelease/commit"
+	"github.com/arsham/gitrelease/notify"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -15,6 +16,8 @@ import (
 var (
 	tag        string
 	printMode  bool
+	editMode   bool
+	dryRunMode bool
 	remote     string
 	version    = "development"
 	currentSha = "N/A"
@@ -22,20 +25,29 @@ var (
 	rootCmd = &cobra.Command{
 		Use:   "gitrelease",
 		Short: "Release commit information of a tag to github",
+		// Errors are printed by cobra.CheckErr in main.
+		SilenceErrors: true,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			if len(args) > 0 && args[0] == "version" {
-				fmt.Printf("gitrelease version %s (%s)\n", version, currentSha)
-				return nil
-			}
-
 			ctx, cancel := signal.NotifyContext(cmd.Context(), syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
 			defer cancel()
-			token := os.Getenv("GITHUB_TOKEN")
-			if token == "" {
-				return errors.New("please export GITHUB_TOKEN")
+			var (
+				token string
+				hooks []notify.Notifier
+			)
+			if !printMode {
+				var err error
+				token, err = githubToken(ctx, dryRunMode)
+				if err != nil {
+					return err
+				}
+				hooks, err = notifiers()
+				if err != nil {
+					return err
+				}
 			}
-			g := &commit.Git{
-				Remote: remote,
+			g, err := newGit()
+			if err != nil {
+				return err
 			}
 
 			user, repo, err := g.RepoInfo(ctx)
@@ -43,7 +55,11 @@ var (
 				return errors.Wrap(err, "can't get repo name")
 			}
 
+			// The first release has no previous tag, and lists all commits.
 			tag1, err := g.PreviousTag(ctx, tag)
+			if errors.Is(err, commit.ErrNoTags) {
+				tag1, err = "", nil
+			}
 			if err != nil {
 				return errors.Wrap(err, "getting previous tag")
 			}
@@ -52,33 +68,149 @@ var (
 			if err != nil {
 				return err
 			}
-			desc := commit.ParseGroups(logs)
+			opts, err := notesOptions()
+			if err != nil {
+				return err
+			}
+			desc := commit.ParseGroups(logs, opts...)
 			if tag == "@" {
 				tag, err = g.LatestTag(ctx)
 				if err != nil {
 					return err
 				}
 			}
+			desc, err = introduce(ctx, g, tag, desc)
+			if err != nil {
+				return err
+			}
+			desc, err = verifyTag(ctx, g, tag, desc)
+			if err != nil {
+				return err
+			}
+
+			// draft is the file the edit is kept in when a later step fails.
+			var draft string
+			if editMode {
+				desc, draft, err = editRelease(ctx, cmd.ErrOrStderr(), g, editor(), tag, desc, dryRunMode)
+				if err != nil {
+					return err
+				}
+			}
+
+			var (
+				milestones []commit.Milestone
+				milestone  *commit.Milestone
+			)
+			if !printMode {
+				milestones, milestone, err = tagMilestone(ctx, g, token, user, repo, tag)
+				if err != nil {
+					return keepEdit(err, draft)
+				}
+				if milestone != nil {
+					desc += "\n\n" + commit.MilestoneLink(*milestone)
+				}
+			}
+
+			if dryRunMode {
+				err := dryRun(ctx, cmd.OutOrStdout(), g, token, user, repo, tag, desc, milestones, milestone, hooks)
+				return keepEdit(err, draft)
+			}
 
 			if printMode {
-				_, err := fmt.Println(desc)
+				if _, err := fmt.Println(desc); err != nil {
+					return keepEdit(err, draft)
+				}
+				if draft != "" {
+					return os.Remove(draft)
+				}
+				return nil
+			}
+
+			err = g.Release(ctx, token, user, repo, tag, desc)
+			if err != nil {
+				return keepEdit(err, draft)
+			}
+			if draft != "" {
+				if err := os.Remove(draft); err != nil {
+					return err
+				}
+			}
+			if err := closeMilestone(ctx, cmd.ErrOrStderr(), g, token, user, repo, milestones, milestone); err != nil {
 				return err
 			}
+			url := releaseURL(user, repo, tag)
+			if err := announce(ctx, cmd.ErrOrStderr(), g, token, user, repo, tag, url, desc); err != nil {
+				return err
+			}
+			return notifyRelease(ctx, cmd.ErrOrStderr(), hooks, notify.Release{
+				Repo:        user + "/" + repo,
+				Tag:         tag,
+				PreviousTag: tag1,
+				URL:         url,
+				Notes:       desc,
+				Entries:     entries(commit.Groups(logs, opts...)),
+			})
+		},
+	}
 
-			return g.Release(ctx, token, user, repo, tag, desc)
+	versionCmd = &cobra.Command{
+		Use:   "version",
+		Short: "Print binary version information",
+		Run: func(cmd *cobra.Command, args []string) {
+			fmt.Printf("gitrelease version %s (%s)\n", version, currentSha)
 		},
 	}
 )
 
 func main() {
-	cobra.CheckErr(rootCmd.Execute())
+	err := rootCmd.Execute()
+	if h := hint(err); h != "" {
+		err = fmt.Errorf("%w\nhint: %s", err, h)
+	}
+	cobra.CheckErr(err)
 }
 
 func init() {
-	cobra.OnInitialize(viper.AutomaticEnv)
+	cobra.OnInitialize(initConfig)
+	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is .gitrelease.yaml in the current directory)")
+	rootCmd.PersistentFlags().String("token-file", "", "read the GitHub token from the file")
+	cobra.CheckErr(viper.BindPFlag("token_file", rootCmd.PersistentFlags().Lookup("token-file")))
+	rootCmd.PersistentFlags().String("app-id", "", "authenticate as the GitHub App with the ID or client ID")
+	cobra.CheckErr(viper.BindPFlag("github_app.id", rootCmd.PersistentFlags().Lookup("app-id")))
+	rootCmd.PersistentFlags().Int64("app-installation-id", 0, "the installation ID of the GitHub App")
+	cobra.CheckErr(viper.BindPFlag("github_app.installation_id", rootCmd.PersistentFlags().Lookup("app-installation-id")))
+	rootCmd.PersistentFlags().String("app-private-key", "", "the private key file of the GitHub App (default is the GITHUB_APP_PRIVATE_KEY environment variable)")
+	cobra.CheckErr(viper.BindPFlag("github_app.private_key", rootCmd.PersistentFlags().Lookup("app-private-key")))
+	rootCmd.PersistentFlags().BoolP("verbose", "v", false, "log the API requests with the authorization redacted")
+	cobra.CheckErr(viper.BindPFlag("verbose", rootCmd.PersistentFlags().Lookup("verbose")))
 	rootCmd.PersistentFlags().StringVarP(&tag, "tag", "t", "@", "tag to produce the logs for. Leave empty for current tag.")
 	rootCmd.PersistentFlags().BoolVarP(&printMode, "print", "p", false, "only print, do not release!")
+	rootCmd.Flags().BoolVarP(&editMode, "edit", "e", false, "edit the notes in $VISUAL or $EDITOR before publishing")
+	rootCmd.Flags().BoolVar(&dryRunMode, "dry-run", false, "show the difference with the existing release without publishing")
 	rootCmd.PersistentFlags().StringVarP(&remote, "remote", "r", "origin", "use a different remote")
+	rootCmd.PersistentFlags().String("merge-strategy", "keep", "how to list merge commits: keep, skip, or pr for using pull request titles")
+	cobra.CheckErr(viper.BindPFlag("merge_strategy", rootCmd.PersistentFlags().Lookup("merge-strategy")))
+	rootCmd.PersistentFlags().String("git-backend", "exec", "how to read the repository: exec runs git, go reads it without git")
+	cobra.CheckErr(viper.BindPFlag("git.backend", rootCmd.PersistentFlags().Lookup("git-backend")))
+	rootCmd.PersistentFlags().String("convention", "conventional", "commit message convention: conventional or gitmoji")
+	cobra.CheckErr(viper.BindPFlag("convention", rootCmd.PersistentFlags().Lookup("convention")))
+	rootCmd.PersistentFlags().Bool("scope-sections", false, "subdivide each section by the scopes of the commits")
+	cobra.CheckErr(viper.BindPFlag("scopes.sections", rootCmd.PersistentFlags().Lookup("scope-sections")))
+	rootCmd.PersistentFlags().Bool("tag-message", false, "add the message of the annotated tag above the notes")
+	cobra.CheckErr(viper.BindPFlag("tag_message.enabled", rootCmd.PersistentFlags().Lookup("tag-message")))
+	rootCmd.PersistentFlags().Bool("verify-tag", false, "refuse to publish if the tag is not signed by a trusted key, and add the signer to the notes")
+	cobra.CheckErr(viper.BindPFlag("verify_tag.enabled", rootCmd.PersistentFlags().Lookup("verify-tag")))
+	rootCmd.PersistentFlags().String("allowed-signers", "", "the allowed signers file for checking the SSH signatures (default is git's gpg.ssh.allowedSignersFile)")
+	cobra.CheckErr(viper.BindPFlag("verify_tag.allowed_signers", rootCmd.PersistentFlags().Lookup("allowed-signers")))
+	rootCmd.Flags().Bool("milestone", false, "close the milestone named after the tag, move its open issues to the next one, and link it in the notes")
+	cobra.CheckErr(viper.BindPFlag("milestones.enabled", rootCmd.Flags().Lookup("milestone")))
+	rootCmd.Flags().Bool("comment", false, "comment on the issues and pull requests referenced in the notes that they are released")
+	cobra.CheckErr(viper.BindPFlag("comments.enabled", rootCmd.Flags().Lookup("comment")))
+	rootCmd.Flags().String("released-label", "", "add the label to the issues and pull requests commented on")
+	cobra.CheckErr(viper.BindPFlag("comments.label", rootCmd.Flags().Lookup("released-label")))
+	includeFlags.register(rootCmd.PersistentFlags(), "include")
+	excludeFlags.register(rootCmd.PersistentFlags(), "exclude")
+	rootCmd.AddCommand(versionCmd)
 
 	rootCmd.SetUsageTemplate(`Usage:{{if .Runnable}}
   {{.UseLine}}{{end}}{{if .HasAvailableSubCommands}}
@@ -93,10 +225,6 @@ Examples:
 Available Commands:{{range .Commands}}{{if (or .IsAvailableCommand (eq .Name "help"))}}
   {{rpad .Name .NamePadding }} {{.Short}}{{end}}{{end}}{{end}}{{if .HasAvailableLocalFlags}}
 
-Available Commands:
-  help        Help about any command
-  version     Print binary version information
-
 Flags:
 {{.LocalFlags.FlagUsages | trimTrailingWhitespaces}}{{end}}{{if .HasAvailableInheritedFlags}}
 
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/arsham/gitrelease/commit"
+	"github.com/spf13/viper"
+)
+
+// tagMilestone returns the milestones of the repo and the one named after the
+// tag, if closing milestones is enabled.
+func tagMilestone(ctx context.Context, g *commit.Git, token, user, repo, tag string) ([]commit.Milestone, *commit.Milestone, error) {
+	if !viper.GetBool("milestones.enabled") {
+		return nil, nil, nil
+	}
+	milestones, err := g.Milestones(ctx, token, user, repo)
+	if err != nil {
+		return nil, nil, err
+	}
+	return milestones, commit.MilestoneForTag(milestones, tag), nil
+}
+
+// closeMilestone moves the open issues of the milestone m to the next one and
+// closes m. The failures are only reported to w, as the release is already
+// published.
+func closeMilestone(ctx context.Context, w io.Writer, g *commit.Git, token, user, repo string, milestones []commit.Milestone, m *commit.Milestone) error {
+	if m == nil {
+		return nil
+	}
+	issues, err := g.MilestoneIssues(ctx, token, user, repo, m.Number)
+	if err != nil {
+		_, err = fmt.Fprintf(w, "warning: %s\n", err)
+		return err
+	}
+	if len(issues) > 0 {
+		next := commit.NextMilestone(milestones, m.Title)
+		if next == nil {
+			_, err = fmt.Fprintf(w, "warning: milestone %s has %d open issues and there is no milestone after it, it is left open\n", m.Title, len(issues))
+			return err
+		}
+		for _, issue := range issues {
+			if err := g.SetMilestone(ctx, token, user, repo, issue, next.Number); err != nil {
+				_, err = fmt.Fprintf(w, "warning: %s, milestone %s is left open\n", err, m.Title)
+				return err
+			}
+		}
+	}
+	if !m.Open() {
+		return nil
+	}
+	if err := g.CloseMilestone(ctx, token, user, repo, m.Number); err != nil {
+		_, err = fmt.Fprintf(w, "warning: %s\n", err)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/arsham/gitrelease/commit"
+	"github.com/arsham/gitrelease/notify"
+	"github.com/pkg/errors"
+	"github.com/spf13/viper"
+)
+
+// webhookConfig is an entry of the notify.webhooks list in the configuration
+// file.
+type webhookConfig struct {
+	Name     string   `mapstructure:"name"`
+	Type     string   `mapstructure:"type"`
+	URL      string   `mapstructure:"url"`
+	Sections []string `mapstructure:"sections"`
+}
+
+// signedConfig is an entry of the notify.signed list in the configuration
+// file.
+type signedConfig struct {
+	Name string `mapstructure:"name"`
+	URL  string `mapstructure:"url"`
+	// SecretEnv is the name of the environment variable holding the secret.
+	// It takes precedence over Secret.
+	SecretEnv string        `mapstructure:"secret_env"`
+	Secret    string        `mapstructure:"secret"`
+	Timeout   time.Duration `mapstructure:"timeout"`
+	Retries   *int          `mapstructure:"retries"`
+	Backoff   time.Duration `mapstructure:"backoff"`
+}
+
+// notifiers returns the webhooks in the configuration file. It is called
+// before publishing, so the mistakes in the configuration stop the release.
+func notifiers() ([]notify.Notifier, error) {
+	onFailure := strings.ToLower(viper.GetString("notify.on_failure"))
+	if onFailure != "" && onFailure != "warn" && onFailure != "fail" {
+		return nil, fmt.Errorf("unknown notify.on_failure value %q, valid values are: warn, fail", onFailure)
+	}
+	var webhooks []webhookConfig
+	if err := viper.UnmarshalKey("notify.webhooks", &webhooks); err != nil {
+		return nil, errors.Wrap(err, "notify.webhooks")
+	}
+	var signed []signedConfig
+	if err := viper.UnmarshalKey("notify.signed", &signed); err != nil {
+		return nil, errors.Wrap(err, "notify.signed")
+	}
+
+	timeout := 10 * time.Second
+	if viper.IsSet("notify.timeout") {
+		timeout = viper.GetDuration("notify.timeout")
+	}
+	ret := make([]notify.Notifier, 0, len(webhooks)+len(signed))
+	for i, c := range webhooks {
+		if c.URL == "" {
+			return nil, fmt.Errorf("notify.webhooks[%d]: url is empty", i)
+		}
+		kind := notify.Kind(strings.ToLower(c.Type))
+		switch kind {
+		case notify.Slack, notify.Mattermost, notify.Teams:
+		default:
+			return nil, fmt.Errorf("notify.webhooks[%d]: unknown type %q, valid values are: %s, %s, %s", i, c.Type, notify.Slack, notify.Mattermost, notify.Teams)
+		}
+		name := c.Name
+		if name == "" {
+			name = c.Type
+		}
+		ret = append(ret, notify.Webhook{
+			Client:   &http.Client{Timeout: timeout},
+			Name:     name,
+			URL:      c.URL,
+			Kind:     kind,
+			Sections: c.Sections,
+		})
+	}
+	for i, c := range signed {
+		if c.URL == "" {
+			return nil, fmt.Errorf("notify.signed[%d]: url is empty", i)
+		}
+		secret := c.Secret
+		if c.SecretEnv != "" {
+			secret = os.Getenv(c.SecretEnv)
+			if secret == "" {
+				return nil, fmt.Errorf("notify.signed[%d]: %s is not set", i, c.SecretEnv)
+			}
+		}
+		w := notify.SignedWebhook{
+			Name:    c.Name,
+			URL:     c.URL,
+			Secret:  secret,
+			Timeout: 10 * time.Second,
+			Retries: 3,
+			Backoff: time.Second,
+		}
+		if w.Name == "" {
+			w.Name = c.URL
+		}
+		if c.Timeout > 0 {
+			w.Timeout = c.Timeout
+		}
+		if c.Retries != nil {
+			w.Retries = *c.Retries
+		}
+		if c.Backoff > 0 {
+			w.Backoff = c.Backoff
+		}
+		w.Client = &http.Client{Timeout: w.Timeout}
+		ret = append(ret, w)
+	}
+	return ret, nil
+}
+
+// entries converts the groups to the entries of the notifications.
+func entries(groups []commit.Group) []notify.Entry {
+	ret := make([]notify.Entry, 0, len(groups))
+	for _, g := range groups {
+		var trailers []notify.Trailer
+		for _, t := range g.Trailers {
+			trailers = append(trailers, notify.Trailer{Key: t.Key, Value: t.Value})
+		}
+		ret = append(ret, notify.Entry{
+			Section:     g.Verb,
+			Scope:       g.Scope(),
+			Description: g.Text(),
+			Breaking:    g.Breaking,
+			Trailers:    trailers,
+		})
+	}
+	return ret
+}
+
+// notifyRelease sends the published release to the hooks. The failures are
+// only reported to w, unless notify.on_failure is set to "fail". The release is
+// kept either way.
+func notifyRelease(ctx context.Context, w io.Writer, hooks []notify.Notifier, r notify.Release) error {
+	if len(hooks) == 0 {
+		return nil
+	}
+	onFailure := strings.ToLower(viper.GetString("notify.on_failure"))
+	errs := notify.All(ctx, hooks, r)
+	if len(errs) == 0 {
+		return nil
+	}
+	msgs := make([]string, 0, len(errs))
+	for _, err := range errs {
+		msgs = append(msgs, err.Error())
+	}
+	if onFailure == "fail" {
+		return fmt.Errorf("the release is published, but notifying failed:\n%s", strings.Join(msgs, "\n"))
+	}
+	_, err := fmt.Fprintf(w, "warning: notifying failed:\n%s\n", strings.Join(msgs, "\n"))
+	return err
+}
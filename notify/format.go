@@ -0,0 +1,192 @@
+package notify
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// slackTextLimit is the maximum length of the text of a Block Kit section.
+const slackTextLimit = 3000
+
+type section struct {
+	name string
+	body string
+}
+
+// splitSections splits the markdown notes by their "### " headings. Any text
+// before the first heading is returned as a section without a name.
+func splitSections(notes string) []section {
+	var sections []section
+	current := section{}
+	var body []string
+	flush := func() {
+		current.body = strings.TrimSpace(strings.Join(body, "\n"))
+		if current.name != "" || current.body != "" {
+			sections = append(sections, current)
+		}
+		body = body[:0]
+	}
+	for _, line := range strings.Split(notes, "\n") {
+		if strings.HasPrefix(line, "### ") {
+			flush()
+			current = section{name: strings.TrimSpace(strings.TrimPrefix(line, "### "))}
+			continue
+		}
+		body = append(body, line)
+	}
+	flush()
+	return sections
+}
+
+// FilterSections returns the notes with only the sections having the names.
+// The text before the first section is always kept. All sections are kept if
+// names is empty.
+func FilterSections(notes string, names []string) string {
+	if len(names) == 0 {
+		return notes
+	}
+	var parts []string
+	for _, s := range splitSections(notes) {
+		switch {
+		case s.name == "":
+			parts = append(parts, s.body)
+		case containsFold(names, s.name):
+			parts = append(parts, "### "+s.name+"\n\n"+s.body)
+		}
+	}
+	return strings.Join(parts, "\n\n\n")
+}
+
+func containsFold(list []string, s string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, s) {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	boldRe    = regexp.MustCompile(`\*\*(.+?)\*\*`)
+	linkRe    = regexp.MustCompile(`\[([^\]]+)\]\(([^)\s]+)\)`)
+	headingRe = regexp.MustCompile(`^#{1,6}\s+(.*)$`)
+	escaper   = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+)
+
+// mrkdwn converts the markdown to the Slack mrkdwn format.
+func mrkdwn(md string) string {
+	lines := strings.Split(escaper.Replace(md), "\n")
+	for i, line := range lines {
+		if m := headingRe.FindStringSubmatch(line); m != nil {
+			line = "*" + m[1] + "*"
+		} else if strings.HasPrefix(line, "- ") {
+			line = "• " + strings.TrimPrefix(line, "- ")
+		}
+		line = boldRe.ReplaceAllString(line, "*$1*")
+		line = linkRe.ReplaceAllString(line, "<$2|$1>")
+		lines[i] = line
+	}
+	return strings.Join(lines, "\n")
+}
+
+func truncate(s string, limit int) string {
+	if len(s) <= limit {
+		return s
+	}
+	r := []rune(s[:limit-len("…")])
+	// Drop the last rune in case it was cut in half.
+	return string(r[:len(r)-1]) + "…"
+}
+
+type slackText struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+type slackBlock struct {
+	Text     *slackText  `json:"text,omitempty"`
+	Type     string      `json:"type"`
+	Elements []slackText `json:"elements,omitempty"`
+}
+
+func slackPayload(r Release) interface{} {
+	blocks := []slackBlock{{
+		Type: "header",
+		Text: &slackText{Type: "plain_text", Text: truncate(r.Title(), 150)},
+	}}
+	for _, s := range splitSections(r.Notes) {
+		text := s.body
+		if s.name != "" {
+			text = "### " + s.name + "\n" + s.body
+		}
+		blocks = append(blocks, slackBlock{
+			Type: "section",
+			Text: &slackText{Type: "mrkdwn", Text: truncate(mrkdwn(text), slackTextLimit)},
+		})
+	}
+	if r.URL != "" {
+		blocks = append(blocks, slackBlock{
+			Type:     "context",
+			Elements: []slackText{{Type: "mrkdwn", Text: fmt.Sprintf("<%s|View the release>", r.URL)}},
+		})
+	}
+	return struct {
+		Text   string       `json:"text"`
+		Blocks []slackBlock `json:"blocks"`
+	}{
+		Text:   r.Title(),
+		Blocks: blocks,
+	}
+}
+
+func mattermostPayload(r Release) interface{} {
+	title := r.Title()
+	if r.URL != "" {
+		title = fmt.Sprintf("[%s](%s)", title, r.URL)
+	}
+	return struct {
+		Text string `json:"text"`
+	}{
+		Text: "#### " + title + "\n\n" + r.Notes,
+	}
+}
+
+type teamsTarget struct {
+	OS  string `json:"os"`
+	URI string `json:"uri"`
+}
+
+type teamsAction struct {
+	Type    string        `json:"@type"`
+	Name    string        `json:"name"`
+	Targets []teamsTarget `json:"targets"`
+}
+
+func teamsPayload(r Release) interface{} {
+	var actions []teamsAction
+	if r.URL != "" {
+		actions = append(actions, teamsAction{
+			Type:    "OpenUri",
+			Name:    "View the release",
+			Targets: []teamsTarget{{OS: "default", URI: r.URL}},
+		})
+	}
+	return struct {
+		Type     string        `json:"@type"`
+		Context  string        `json:"@context"`
+		Summary  string        `json:"summary"`
+		Title    string        `json:"title"`
+		Text     string        `json:"text"`
+		Markdown bool          `json:"markdown"`
+		Actions  []teamsAction `json:"potentialAction,omitempty"`
+	}{
+		Type:     "MessageCard",
+		Context:  "https://schema.org/extensions",
+		Summary:  r.Title(),
+		Title:    r.Title(),
+		Text:     r.Notes,
+		Markdown: true,
+		Actions:  actions,
+	}
+}
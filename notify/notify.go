@@ -0,0 +1,152 @@
+// Package notify posts the release notes to chat services after a release is
+// published.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Release is the information about a published release.
+type Release struct {
+	// Repo is the repository in the "user/repo" form.
+	Repo string
+	Tag  string
+	// PreviousTag is the tag the notes start from. It is empty for the first
+	// release.
+	PreviousTag string
+	// URL is the address of the release page.
+	URL string
+	// Notes are the release notes in markdown.
+	Notes string
+	// Entries are the lines of the notes.
+	Entries []Entry
+}
+
+// An Entry is a line of the release notes.
+type Entry struct {
+	Section     string `json:"section"`
+	Scope       string `json:"scope,omitempty"`
+	Description string `json:"description"`
+	Breaking    bool   `json:"breaking"`
+	// Trailers are the trailers of the commit, e.g. Co-authored-by.
+	Trailers []Trailer `json:"trailers,omitempty"`
+}
+
+// A Trailer is a "Key: value" line at the end of a commit message.
+type Trailer struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// DefaultTimeout limits the requests of the notifiers that have no Client.
+const DefaultTimeout = 30 * time.Second
+
+// defaultClient is used by the notifiers that have no Client.
+var defaultClient = &http.Client{Timeout: DefaultTimeout}
+
+// clientOrDefault returns the client, or the defaultClient if it is nil.
+func clientOrDefault(client *http.Client) *http.Client {
+	if client == nil {
+		return defaultClient
+	}
+	return client
+}
+
+// A Notifier sends the release to a service.
+type Notifier interface {
+	Notify(ctx context.Context, r Release) error
+}
+
+// Title returns a one line summary of the release.
+func (r Release) Title() string {
+	return fmt.Sprintf("%s %s is released", r.Repo, r.Tag)
+}
+
+// Kind is the type of the service the webhook belongs to, which decides the
+// format of the payload.
+type Kind string
+
+const (
+	// Slack posts the notes as Block Kit sections in mrkdwn format.
+	Slack Kind = "slack"
+	// Mattermost posts the notes in markdown.
+	Mattermost Kind = "mattermost"
+	// Teams posts the notes as a MessageCard.
+	Teams Kind = "teams"
+)
+
+// Webhook posts the release notes to an incoming webhook.
+type Webhook struct {
+	// Client is used for posting the payloads. A client with the
+	// DefaultTimeout is used if nil.
+	Client *http.Client
+	// Name identifies the webhook in the errors.
+	Name string
+	URL  string
+	Kind Kind
+	// Sections limits the notes to the sections with these names, e.g.
+	// "Feature". All sections are posted if empty.
+	Sections []string
+}
+
+// Notify posts the release notes to the webhook.
+func (w Webhook) Notify(ctx context.Context, r Release) error {
+	r.Notes = FilterSections(r.Notes, w.Sections)
+
+	var payload interface{}
+	switch w.Kind {
+	case Slack:
+		payload = slackPayload(r)
+	case Mattermost:
+		payload = mattermostPayload(r)
+	case Teams:
+		payload = teamsPayload(r)
+	default:
+		return fmt.Errorf("%s: unknown webhook type %q, valid values are: %s, %s, %s", w.Name, w.Kind, Slack, Mattermost, Teams)
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return errors.Wrap(err, "marshalling payload")
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(body))
+	if err != nil {
+		return errors.Wrapf(err, "%s: creating request", w.Name)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := clientOrDefault(w.Client).Do(req)
+	if err != nil {
+		return errors.Wrapf(err, "%s: posting notes", w.Name)
+	}
+	// nolint:errcheck // it's ok.
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		// nolint:errcheck // only used for the error message.
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s: posting notes: %s: %s", w.Name, resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
+
+// All sends the release to all notifiers and returns the errors of the ones
+// that failed. It doesn't stop on failures.
+func All(ctx context.Context, notifiers []Notifier, r Release) []error {
+	var errs []error
+	for _, n := range notifiers {
+		if err := n.Notify(ctx, r); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
@@ -0,0 +1,185 @@
+package notify_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/arsham/gitrelease/notify"
+	"github.com/google/go-cmp/cmp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const notes = "### Feature\n\n- **Api:** Add [PLAT-1](https://jira/PLAT-1) & more\n\n\n### Fix\n\n- Something"
+
+var release = notify.Release{
+	Repo:  "arsham/gitrelease",
+	Tag:   "v0.1.0",
+	URL:   "https://github.com/arsham/gitrelease/releases/tag/v0.1.0",
+	Notes: notes,
+}
+
+func newServer(t *testing.T, status int) (*httptest.Server, *map[string]interface{}) {
+	t.Helper()
+	got := map[string]interface{}{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &got
+}
+
+func TestWebhook(t *testing.T) {
+	t.Parallel()
+	t.Run("Slack", testWebhookSlack)
+	t.Run("Mattermost", testWebhookMattermost)
+	t.Run("Teams", testWebhookTeams)
+	t.Run("Sections", testWebhookSections)
+	t.Run("Errors", testWebhookErrors)
+	t.Run("Timeout", testWebhookTimeout)
+}
+
+func testWebhookSlack(t *testing.T) {
+	t.Parallel()
+	srv, got := newServer(t, http.StatusOK)
+	w := notify.Webhook{URL: srv.URL, Kind: notify.Slack}
+	require.NoError(t, w.Notify(context.Background(), release))
+
+	want := map[string]interface{}{
+		"text": "arsham/gitrelease v0.1.0 is released",
+		"blocks": []interface{}{
+			map[string]interface{}{
+				"type": "header",
+				"text": map[string]interface{}{"type": "plain_text", "text": "arsham/gitrelease v0.1.0 is released"},
+			},
+			map[string]interface{}{
+				"type": "section",
+				"text": map[string]interface{}{
+					"type": "mrkdwn",
+					"text": "*Feature*\n• *Api:* Add <https://jira/PLAT-1|PLAT-1> &amp; more",
+				},
+			},
+			map[string]interface{}{
+				"type": "section",
+				"text": map[string]interface{}{"type": "mrkdwn", "text": "*Fix*\n• Something"},
+			},
+			map[string]interface{}{
+				"type": "context",
+				"elements": []interface{}{map[string]interface{}{
+					"type": "mrkdwn",
+					"text": "<https://github.com/arsham/gitrelease/releases/tag/v0.1.0|View the release>",
+				}},
+			},
+		},
+	}
+	if diff := cmp.Diff(want, *got); diff != "" {
+		t.Errorf("(-want +got):\n%s", diff)
+	}
+}
+
+func testWebhookMattermost(t *testing.T) {
+	t.Parallel()
+	srv, got := newServer(t, http.StatusOK)
+	w := notify.Webhook{URL: srv.URL, Kind: notify.Mattermost}
+	require.NoError(t, w.Notify(context.Background(), release))
+
+	want := "#### [arsham/gitrelease v0.1.0 is released](" + release.URL + ")\n\n" + notes
+	assert.Equal(t, want, (*got)["text"])
+}
+
+func testWebhookTeams(t *testing.T) {
+	t.Parallel()
+	srv, got := newServer(t, http.StatusOK)
+	w := notify.Webhook{URL: srv.URL, Kind: notify.Teams}
+	require.NoError(t, w.Notify(context.Background(), release))
+
+	assert.Equal(t, "MessageCard", (*got)["@type"])
+	assert.Equal(t, "arsham/gitrelease v0.1.0 is released", (*got)["title"])
+	assert.Equal(t, notes, (*got)["text"])
+	actions, ok := (*got)["potentialAction"].([]interface{})
+	require.True(t, ok)
+	assert.Len(t, actions, 1)
+}
+
+func testWebhookSections(t *testing.T) {
+	t.Parallel()
+	srv, got := newServer(t, http.StatusOK)
+	w := notify.Webhook{URL: srv.URL, Kind: notify.Mattermost, Sections: []string{"fix"}}
+	require.NoError(t, w.Notify(context.Background(), release))
+
+	text, ok := (*got)["text"].(string)
+	require.True(t, ok)
+	assert.True(t, strings.HasSuffix(text, "\n\n### Fix\n\n- Something"), text)
+	assert.NotContains(t, text, "Feature")
+}
+
+func testWebhookErrors(t *testing.T) {
+	t.Parallel()
+	srv, _ := newServer(t, http.StatusForbidden)
+	ok, _ := newServer(t, http.StatusOK)
+	webhooks := []notify.Notifier{
+		notify.Webhook{Name: "forbidden", URL: srv.URL, Kind: notify.Slack},
+		notify.Webhook{Name: "ok", URL: ok.URL, Kind: notify.Slack},
+		notify.Webhook{Name: "unknown", URL: ok.URL, Kind: "irc"},
+	}
+	errs := notify.All(context.Background(), webhooks, release)
+	require.Len(t, errs, 2)
+	assert.Contains(t, errs[0].Error(), "forbidden")
+	assert.Contains(t, errs[0].Error(), "403")
+	assert.Contains(t, errs[1].Error(), "unknown webhook type")
+}
+
+func TestFilterSections(t *testing.T) {
+	t.Parallel()
+	notes := "An intro.\n\n### Feature\n\n- A\n\n#### Api\n\n- B\n\n\n### Fix\n\n- C\n\n\n### Docs\n\n- D"
+	tcs := map[string]struct {
+		names []string
+		want  string
+	}{
+		"all":  {want: notes},
+		"one":  {names: []string{"Fix"}, want: "An intro.\n\n\n### Fix\n\n- C"},
+		"sub":  {names: []string{"feature", "docs"}, want: "An intro.\n\n\n### Feature\n\n- A\n\n#### Api\n\n- B\n\n\n### Docs\n\n- D"},
+		"none": {names: []string{"Chore"}, want: "An intro."},
+	}
+	for name, tc := range tcs {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			got := notify.FilterSections(notes, tc.names)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("(-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func testWebhookTimeout(t *testing.T) {
+	t.Parallel()
+	stuck := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-stuck:
+		case <-r.Context().Done():
+		}
+	}))
+	t.Cleanup(srv.Close)
+	t.Cleanup(func() { close(stuck) })
+
+	w := notify.Webhook{
+		Client: &http.Client{Timeout: 50 * time.Millisecond},
+		Name:   "stuck",
+		URL:    srv.URL,
+		Kind:   notify.Slack,
+	}
+	err := w.Notify(context.Background(), release)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "stuck: posting notes")
+}
@@ -0,0 +1,135 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// SignatureHeader holds the HMAC-SHA256 signature of the body of the
+	// SignedWebhook requests, in the "sha256=<hex>" form.
+	SignatureHeader = "X-Gitrelease-Signature"
+	// EventHeader holds the type of the event of the SignedWebhook requests.
+	EventHeader = "X-Gitrelease-Event"
+)
+
+// Payload is the JSON document the SignedWebhook posts.
+type Payload struct {
+	Event       string  `json:"event"`
+	Repo        string  `json:"repo"`
+	Tag         string  `json:"tag"`
+	PreviousTag string  `json:"previous_tag"`
+	URL         string  `json:"url"`
+	Notes       string  `json:"notes"`
+	Entries     []Entry `json:"entries"`
+}
+
+// SignedWebhook posts the release as a Payload to any endpoint. The body is
+// signed with the Secret, and the requests are retried on network errors and
+// server errors.
+type SignedWebhook struct {
+	// Client is used for posting the payloads. A client with the
+	// DefaultTimeout is used if nil.
+	Client *http.Client
+	// Name identifies the webhook in the errors.
+	Name string
+	URL  string
+	// Secret is the key for signing the body. The SignatureHeader is not set
+	// if empty.
+	Secret string
+	// Timeout limits each attempt. There is no limit if zero.
+	Timeout time.Duration
+	// Retries is the number of attempts after the first one fails.
+	Retries int
+	// Backoff is the delay before the first retry, which is doubled after
+	// each attempt.
+	Backoff time.Duration
+}
+
+// Sign returns the value of the SignatureHeader for the body.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body) // nolint:errcheck // it never returns an error.
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Notify posts the release to the webhook.
+func (w SignedWebhook) Notify(ctx context.Context, r Release) error {
+	entries := r.Entries
+	if entries == nil {
+		entries = []Entry{}
+	}
+	body, err := json.Marshal(Payload{
+		Event:       "release",
+		Repo:        r.Repo,
+		Tag:         r.Tag,
+		PreviousTag: r.PreviousTag,
+		URL:         r.URL,
+		Notes:       r.Notes,
+		Entries:     entries,
+	})
+	if err != nil {
+		return errors.Wrap(err, "marshalling payload")
+	}
+
+	backoff := w.Backoff
+	for attempt := 0; ; attempt++ {
+		retry, err := w.post(ctx, body)
+		if err == nil {
+			return nil
+		}
+		if !retry || attempt >= w.Retries {
+			return errors.Wrapf(err, "%s: posting release", w.Name)
+		}
+		select {
+		case <-ctx.Done():
+			return errors.Wrapf(ctx.Err(), "%s: posting release", w.Name)
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// post sends the body once. It returns true if the request can be retried.
+func (w SignedWebhook) post(ctx context.Context, body []byte) (bool, error) {
+	if w.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, w.Timeout)
+		defer cancel()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, errors.Wrap(err, "creating request")
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, "release")
+	if w.Secret != "" {
+		req.Header.Set(SignatureHeader, Sign(w.Secret, body))
+	}
+
+	resp, err := clientOrDefault(w.Client).Do(req)
+	if err != nil {
+		return true, err
+	}
+	// nolint:errcheck // it's ok.
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	// nolint:errcheck // only used for the error message.
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	retry := resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests
+	return retry, fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(msg)))
+}
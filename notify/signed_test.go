@@ -0,0 +1,116 @@
+package notify_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/arsham/gitrelease/notify"
+	"github.com/google/go-cmp/cmp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignedWebhook(t *testing.T) {
+	t.Parallel()
+	t.Run("Payload", testSignedWebhookPayload)
+	t.Run("Retries", testSignedWebhookRetries)
+	t.Run("ClientError", testSignedWebhookClientError)
+	t.Run("Timeout", testSignedWebhookTimeout)
+}
+
+func testSignedWebhookPayload(t *testing.T) {
+	t.Parallel()
+	r := release
+	r.PreviousTag = "v0.0.9"
+	r.Entries = []notify.Entry{
+		{Section: "Feature", Scope: "Api", Description: "Add x", Breaking: true},
+		{Section: "Fix", Description: "Something"},
+	}
+
+	var got notify.Payload
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, err := io.ReadAll(req.Body)
+		assert.NoError(t, err)
+		assert.Equal(t, notify.Sign("secret", body), req.Header.Get(notify.SignatureHeader))
+		assert.Equal(t, "release", req.Header.Get(notify.EventHeader))
+		assert.NoError(t, json.Unmarshal(body, &got))
+	}))
+	defer srv.Close()
+
+	w := notify.SignedWebhook{URL: srv.URL, Secret: "secret"}
+	require.NoError(t, w.Notify(context.Background(), r))
+	want := notify.Payload{
+		Event:       "release",
+		Repo:        r.Repo,
+		Tag:         r.Tag,
+		PreviousTag: r.PreviousTag,
+		URL:         r.URL,
+		Notes:       r.Notes,
+		Entries:     r.Entries,
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("(-want +got):\n%s", diff)
+	}
+}
+
+func testSignedWebhookRetries(t *testing.T) {
+	t.Parallel()
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	}))
+	defer srv.Close()
+
+	w := notify.SignedWebhook{URL: srv.URL, Retries: 2, Backoff: time.Millisecond}
+	require.NoError(t, w.Notify(context.Background(), release))
+	assert.EqualValues(t, 3, atomic.LoadInt32(&calls))
+
+	atomic.StoreInt32(&calls, 0)
+	w.Retries = 1
+	err := w.Notify(context.Background(), release)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "502")
+	assert.EqualValues(t, 2, atomic.LoadInt32(&calls))
+}
+
+func testSignedWebhookClientError(t *testing.T) {
+	t.Parallel()
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer srv.Close()
+
+	w := notify.SignedWebhook{Name: "hook", URL: srv.URL, Retries: 3, Backoff: time.Millisecond}
+	err := w.Notify(context.Background(), release)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "hook")
+	assert.Contains(t, err.Error(), "401")
+	assert.EqualValues(t, 1, atomic.LoadInt32(&calls), "client errors are not retried")
+}
+
+func testSignedWebhookTimeout(t *testing.T) {
+	t.Parallel()
+	var calls int32
+	stuck := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			<-stuck
+		}
+	}))
+	defer srv.Close()
+	defer close(stuck)
+
+	w := notify.SignedWebhook{URL: srv.URL, Timeout: 50 * time.Millisecond, Retries: 1, Backoff: time.Millisecond}
+	require.NoError(t, w.Notify(context.Background(), release))
+	assert.EqualValues(t, 2, atomic.LoadInt32(&calls))
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"os"
+
+	"github.com/arsham/gitrelease/credential"
+	"github.com/pkg/errors"
+	"github.com/spf13/viper"
+)
+
+// githubToken returns the first token found in the --token-file, the
+// environment, the gh CLI hosts file, ~/.netrc or git's credential helpers. If
+// optional is true, an empty token is returned when there is none. When a
+// GitHub App is configured, only the installation token of the app is used.
+func githubToken(ctx context.Context, optional bool) (string, error) {
+	if viper.GetString("github_app.id") != "" {
+		app, err := githubApp()
+		if err != nil {
+			return "", err
+		}
+		return app.Token(ctx)
+	}
+	chain := credential.Default(viper.GetString("token_file"), "github.com", "")
+	token, _, err := chain.Token(ctx)
+	if errors.Is(err, credential.ErrNotFound) {
+		if optional {
+			return "", nil
+		}
+		return "", errors.New("no GitHub token found, please export GITHUB_TOKEN, log in with gh, or use --token-file")
+	}
+	return token, err
+}
+
+// githubApp returns the app in the configuration. The private key is read from
+// the file, or from the GITHUB_APP_PRIVATE_KEY environment variable if no file
+// is given.
+func githubApp() (credential.App, error) {
+	installation := viper.GetInt64("github_app.installation_id")
+	if installation == 0 {
+		return credential.App{}, errors.New("github_app.installation_id is required with github_app.id")
+	}
+	var key []byte
+	if path := viper.GetString("github_app.private_key"); path != "" {
+		var err error
+		key, err = os.ReadFile(path)
+		if err != nil {
+			return credential.App{}, errors.Wrap(err, "reading the private key of the app")
+		}
+	} else {
+		key = []byte(os.Getenv("GITHUB_APP_PRIVATE_KEY"))
+	}
+	if len(key) == 0 {
+		return credential.App{}, errors.New("the private key of the app is required, use --app-private-key or GITHUB_APP_PRIVATE_KEY")
+	}
+	return credential.App{
+		ID:             viper.GetString("github_app.id"),
+		InstallationID: installation,
+		PrivateKey:     key,
+		Client:         apiClient(),
+	}, nil
+}